@@ -0,0 +1,28 @@
+package main
+
+// Armageddon is the tie-break game: White gets more time but has to win, as
+// a draw counts as a win for Black. It's played on the classic board, so
+// the player, as White, takes the extra time and Frank the draw odds, and a
+// win pays armageddonBonus percent more for it.
+const (
+	armageddonBonus      = 25
+	armageddonBlackShare = 80 // Black's clock as a percent of White's: four minutes to five
+)
+
+// NewArmageddonGame is NewGame set up for armageddon.
+func NewArmageddonGame(wager int, minutes int) *Game {
+	g := NewGame(wager, minutes)
+	g.armageddon = true
+	g.blackTime = g.blackTime * armageddonBlackShare / 100
+	g.startTimes[Black] = g.blackTime
+	return g
+}
+
+// decisive is the winner to book for a game that ended with winner, -1 for a
+// draw: in armageddon a draw goes to Black.
+func (g *Game) decisive(winner int) int {
+	if winner < 0 && g.armageddon {
+		return int(Black)
+	}
+	return winner
+}
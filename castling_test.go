@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoseCastlingRights(t *testing.T) {
+	const (
+		white = "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+		black = "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1"
+	)
+	tests := []struct {
+		name, fen, move, want string
+	}{
+		{"white king", white, "e1e2", "kq"},
+		{"white king-side rook", white, "h1h2", "Qkq"},
+		{"white queen-side rook", white, "a1a2", "Kkq"},
+		{"black king", black, "e8e7", "KQ"},
+		{"black king-side rook", black, "h8h7", "KQq"},
+		{"black queen-side rook", black, "a8a7", "KQk"},
+		{"rooks trade on a8", white, "a1a8", "Kk"},
+		{"rooks trade on h1", black, "h8h1", "Qq"},
+		{"bishop takes h8", "r3k2r/8/8/8/8/8/1B6/R3K2R w KQkq - 0 1", "b2h8", "KQq"},
+		{"bishop takes a1", "r3k2r/6b1/8/8/8/8/8/R3K2R b KQkq - 0 1", "g7a1", "Kkq"},
+	}
+	for _, tt := range tests {
+		g := NewGame(0, 5)
+		if err := g.LoadFEN(tt.fen); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		m, err := g.parseMove(tt.move)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		g.executeMove(m)
+		if got := strings.Fields(g.FEN())[2]; got != tt.want {
+			t.Errorf("%s: castling = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
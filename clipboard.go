@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// copyToClipboard puts s on the system clipboard through the platform's own
+// command-line tool, which on Linux means whichever of wl-copy, xclip and
+// xsel is installed.
+func copyToClipboard(s string) error {
+	var cmds [][]string
+	switch runtime.GOOS {
+	case "windows":
+		cmds = [][]string{{"clip"}}
+	case "darwin":
+		cmds = [][]string{{"pbcopy"}}
+	default:
+		cmds = [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+	for _, c := range cmds {
+		if _, err := exec.LookPath(c[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Stdin = strings.NewReader(s)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no clipboard tool found")
+}
+
+// copyMoves copies the moves so far, numbered and space-separated, for
+// pasting into a chat or an analysis board.
+func (g *Game) copyMoves() {
+	if len(g.history) == 0 {
+		return
+	}
+	if err := copyToClipboard(strings.Join(g.numberedMoves(), " ")); err != nil {
+		fmt.Printf("copy: %v\n", err)
+		g.currentDialog = "Couldn't copy the moves."
+		return
+	}
+	g.currentDialog = "Moves copied."
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// A staked game opens with a "3, 2, 1, GO!" countdown, countdownStep frames
+// to each, while the clocks wait and input is ignored.
+const countdownStep = 45
+
+// drawCountdown shows the count over the board at three times the usual text
+// size.
+func (g *Game) drawCountdown(screen *ebiten.Image) {
+	label := "GO!"
+	if n := (g.countdown - 1) / countdownStep; n > 0 {
+		label = strconv.Itoa(n)
+	}
+	vector.FillRect(screen, 40, 56, 80, 48, color.RGBA{0, 0, 0, 220}, false)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(3, 3)
+	op.GeoM.Translate(float64(screenW-21*len(label))/2, 94)
+	op.ColorScale.ScaleWithColor(color.RGBA{255, 215, 0, 255})
+	text.DrawWithOptions(screen, label, uiFace(), op)
+}
@@ -0,0 +1,51 @@
+package main
+
+import "strings"
+
+// A cue is an event the player would otherwise only hear, or easily miss:
+// a move, a capture, a check, or their clock running low. With the Visual
+// cues setting on, each flashes the board's frame in its own color.
+type cue int
+
+const (
+	cueMove cue = iota
+	cueCapture
+	cueCheck
+	cueLowTime
+)
+
+const (
+	cueFrames   = 30                  // how long a flash lasts
+	lowTimeMark = 10 * ticksPerSecond // frames left on the clock that count as low
+)
+
+// signal starts a flash for k. Games nobody watches, such as the menu's demo
+// and search clones, stay dark, as they stay silent.
+func (g *Game) signal(k cue) {
+	if g.demo {
+		return
+	}
+	g.lastCue, g.cueFrames = k, cueFrames
+}
+
+// signalMove flashes for the move just finished, as the most urgent of the
+// cues it sets off.
+func (g *Game) signalMove() {
+	san := g.history[len(g.history)-1].SAN
+	switch {
+	case strings.HasSuffix(san, "+") || strings.HasSuffix(san, "#"):
+		g.signal(cueCheck)
+	case strings.Contains(san, "x"):
+		g.signal(cueCapture)
+	default:
+		g.signal(cueMove)
+	}
+}
+
+// warnLowTime flashes once, on the frame a clock of t frames, dt of which
+// have just run off it, passes below lowTimeMark.
+func (g *Game) warnLowTime(t, dt float64) {
+	if t < lowTimeMark && t+dt >= lowTimeMark {
+		g.signal(cueLowTime)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+// Dead positions are ones where no sequence of legal moves can end in mate,
+// so the game is drawn on the spot. Deciding that in general means searching
+// every line; this only recognises two common shapes:
+//
+//   - neither side has mating material: bare kings, a lone knight or
+//     bishop, or a bishop each on the same square color, and
+//   - kings and pawns only, with every pawn blocked head-on and neither king
+//     able to walk up to an enemy pawn it could take.
+//
+// Anything else, however hopeless, is played out.
+
+// isDeadPosition reports whether the position is one of the dead shapes above.
+func (g *Game) isDeadPosition() bool {
+	if g.isInsufficientMaterial(White) && g.isInsufficientMaterial(Black) {
+		// a minor piece each can still mate, unless both are bishops on
+		// the same color
+		return g.pieceCount(White)+g.pieceCount(Black) <= 3 || g.sameColorBishops()
+	}
+	return g.isPawnLock()
+}
+
+// isInsufficientMaterial reports whether c could never mate even against a
+// bare king: c has at most one knight or bishop and nothing else but the king.
+func (g *Game) isInsufficientMaterial(c Color) bool {
+	minors := 0
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			p := g.board[y][x]
+			if p == nil || p.Color != c || p.Type == King {
+				continue
+			}
+			if p.Type != Knight && p.Type != Bishop {
+				return false
+			}
+			minors++
+		}
+	}
+	return minors <= 1
+}
+
+// timeoutIsDraw reports whether c running out of time is a draw because the
+// other side could never mate by any series of legal moves. A bare king never
+// can. A lone knight or bishop can only mate with c's own men boxing its king
+// in, so that only counts against a bare king. And when every piece left is a
+// bishop on one square color, no king can be driven off the other color.
+func (g *Game) timeoutIsDraw(c Color) bool {
+	other := 1 - c
+	if g.pieceCount(other) == 1 {
+		return true
+	}
+	if g.isInsufficientMaterial(other) && g.pieceCount(c) == 1 {
+		return true
+	}
+	return g.sameColorBishops()
+}
+
+// sameColorBishops reports whether every piece but the kings is a bishop,
+// all on squares of one color.
+func (g *Game) sameColorBishops() bool {
+	squareColor := -1
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			p := g.board[y][x]
+			if p == nil || p.Type == King {
+				continue
+			}
+			if p.Type != Bishop || (squareColor >= 0 && squareColor != (x+y)%2) {
+				return false
+			}
+			squareColor = (x + y) % 2
+		}
+	}
+	return true
+}
+
+// pieceCount counts c's pieces, king included.
+func (g *Game) pieceCount(c Color) int {
+	n := 0
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if p := g.board[y][x]; p != nil && p.Color == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// isPawnLock reports a kings-and-pawns position where the pawns can never
+// move again: each is blocked by the pawn in front, none can capture, and no
+// king can reach a square next to an enemy pawn that no pawn defends. The
+// kings then wander forever and nothing can give check.
+func (g *Game) isPawnLock() bool {
+	if g.epX >= 0 {
+		return false
+	}
+	pawns := 0
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			p := g.board[y][x]
+			if p == nil || p.Type == King {
+				continue
+			}
+			if p.Type != Pawn {
+				return false
+			}
+			pawns++
+			dir := pawnDir(p.Color)
+			if y+dir < 0 || y+dir > 7 {
+				return false // on its last rank, waiting to be promoted
+			}
+			if f := g.board[y+dir][x]; f == nil || f.Type != Pawn {
+				return false
+			}
+			for _, cx := range []int{x - 1, x + 1} {
+				if cx >= 0 && cx < 8 {
+					if q := g.board[y+dir][cx]; q != nil && q.Color != p.Color {
+						return false
+					}
+				}
+			}
+		}
+	}
+	if pawns == 0 {
+		return false
+	}
+	return !g.kingCanRaid(White) && !g.kingCanRaid(Black)
+}
+
+// kingCanRaid flood-fills the squares c's king can walk to past the pawn
+// wall and reports whether any of them touches an undefended enemy pawn.
+func (g *Game) kingCanRaid(c Color) bool {
+	var seen [8][8]bool
+	var stack []Pos
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if p := g.board[y][x]; p != nil && p.Type == King && p.Color == c {
+				stack = append(stack, Pos{x, y})
+				seen[y][x] = true
+			}
+		}
+	}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				x, y := s.X+dx, s.Y+dy
+				if !g.onBoard(x, y) || seen[y][x] {
+					continue
+				}
+				seen[y][x] = true
+				if p := g.board[y][x]; p != nil && p.Type == Pawn {
+					if p.Color != c && !g.pawnDefended(x, y) {
+						return true
+					}
+					continue
+				}
+				if g.pawnAttacks(x, y, 1-c) {
+					continue
+				}
+				stack = append(stack, Pos{x, y})
+			}
+		}
+	}
+	return false
+}
+
+// pawnDefended reports whether the pawn on x,y is guarded by one of its own
+// pawns, which in a pawn lock can never move away.
+func (g *Game) pawnDefended(x, y int) bool {
+	return g.pawnAttacks(x, y, g.board[y][x].Color)
+}
+
+// pawnAttacks reports whether a pawn of color by attacks x,y.
+func (g *Game) pawnAttacks(x, y int, by Color) bool {
+	py := y - pawnDir(by)
+	if py < 0 || py > 7 {
+		return false
+	}
+	for _, px := range []int{x - 1, x + 1} {
+		if px >= 0 && px < 8 {
+			if p := g.board[py][px]; p != nil && p.Type == Pawn && p.Color == by {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pawnDir is the row step of c's pawns: White moves up the board.
+func pawnDir(c Color) int {
+	if c == White {
+		return -1
+	}
+	return 1
+}
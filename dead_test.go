@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+// While the piece picker is up the pawn still stands on its last rank, with
+// no square in front of it.
+func TestPawnLockWhilePromoting(t *testing.T) {
+	g := NewGame(0, 5)
+	if err := g.LoadFEN("7k/P7/8/8/8/8/8/K7 w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	g.autoQueen = false
+	m, err := g.parseMove("a7a8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Promo = Pawn // as a click leaves it, with no piece named
+	g.executeMove(m)
+	if !g.promoting {
+		t.Fatal("no piece picker for a7a8")
+	}
+	if g.isPawnLock() {
+		t.Error("a pawn on its last rank counts as locked")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// debugHUD is the F3 overlay for development: the position as FEN and its
+// fields spelled out, the hustler's last search, and the frame and tick
+// rates. It sits over the top of the board during a game.
+var debugHUD bool
+
+// debugWidth is how many characters of the debug HUD fit across the canvas.
+const debugWidth = 22
+
+// debugLines is what the debug HUD shows, the FEN's placement broken over as
+// many lines as it takes.
+func (g *Game) debugLines() []string {
+	f := strings.Fields(g.FEN())
+	var lines []string
+	for s := f[0]; s != ""; s = s[min(len(s), debugWidth):] {
+		lines = append(lines, s[:min(len(s), debugWidth)])
+	}
+	turn := "WHITE"
+	if g.activeColor == Black {
+		turn = "BLACK"
+	}
+	return append(lines,
+		fmt.Sprintf("TURN %s MOVE %s", turn, f[5]),
+		fmt.Sprintf("CASTLE %s EP %s", f[2], f[3]),
+		fmt.Sprintf("HALFMOVES %s", f[4]),
+		fmt.Sprintf("D%d N%d", g.lastSearch.depth, g.lastSearch.nodes),
+		fmt.Sprintf("FPS %.0f TPS %.0f", ebiten.ActualFPS(), ebiten.ActualTPS()),
+	)
+}
+
+// drawDebugHUD draws the debug HUD in a dark box at the top of the canvas.
+func (g *Game) drawDebugHUD(screen *ebiten.Image) {
+	lines := g.debugLines()
+	vector.FillRect(screen, 0, 0, screenW, float32(len(lines)*12+5), color.RGBA{0, 0, 0, 210}, false)
+	for i, line := range lines {
+		drawText(screen, line, 3, 12+i*12, color.RGBA{0, 255, 150, 255})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Dragging is another way to make a click move: press on a piece, which
+// selects it, and let go over where it should go. While it's in the air
+// its legal squares are marked, and it snaps to the nearest of them within
+// dropReach of the pointer, so a drop a little off a 16-pixel square still
+// lands. Let go anywhere else and it flies home.
+const (
+	dragSlop  = 3  // pixels the pointer moves before a press is a drag
+	dropReach = 12 // pixels from a legal square's center that still snap to it
+)
+
+// pressDrag notes a press at x, y that may become a drag, if it left one of
+// the player's pieces selected.
+func (g *Game) pressDrag(x, y int) {
+	bx, by, ok := g.boardSquare(x, y)
+	if !ok || bx != g.selectedX || by != g.selectedY {
+		return
+	}
+	g.drag = drag{from: Pos{bx, by}, pressX: x, pressY: y, x: x, y: y, active: true}
+}
+
+// updateDrag follows the pointer while a press is held, and on release drops
+// the piece: on its snapped square as a click there would, or back home.
+func (g *Game) updateDrag() {
+	d := &g.drag
+	if !d.active {
+		return
+	}
+	if x, y, held := pointerHeld(); held {
+		d.x, d.y = x, y
+		if abs(x-d.pressX)+abs(y-d.pressY) > dragSlop {
+			d.moving = true
+		}
+		return
+	}
+	d.active = false
+	if !d.moving {
+		return
+	}
+	d.moving = false
+	if to, ok := g.dropSquare(); ok {
+		px, py := g.renderSquare(to.X, to.Y)
+		g.clickBoard(px, py)
+		return
+	}
+	if p := g.board[d.from.Y][d.from.X]; p != nil {
+		g.snap = snapBack{x: float64(d.x - tileSize/2), y: float64(d.y - tileSize/2), to: d.from, sprite: p.SpriteID, frames: snapFrames}
+	}
+}
+
+// dragDests lists where the dragged piece can legally go.
+func (g *Game) dragDests() []Pos {
+	var dests []Pos
+	for _, m := range g.LegalMoves(g.activeColor) {
+		if m.From == g.drag.from {
+			dests = append(dests, m.To)
+		}
+	}
+	return dests
+}
+
+// dropSquare is the legal square nearest the pointer, if one is within
+// dropReach of it.
+func (g *Game) dropSquare() (Pos, bool) {
+	best, bestDist := Pos{}, -1
+	for _, to := range g.dragDests() {
+		px, py := g.renderSquare(to.X, to.Y)
+		dx, dy := g.drag.x-(px+tileSize/2), g.drag.y-(py+tileSize/2)
+		if dist := dx*dx + dy*dy; dist <= dropReach*dropReach && (bestDist < 0 || dist < bestDist) {
+			best, bestDist = to, dist
+		}
+	}
+	return best, bestDist >= 0
+}
+
+// draggedAway reports whether the piece on square s is being drawn
+// somewhere else: in the air, or on its way home.
+func (g *Game) draggedAway(s Pos) bool {
+	return g.drag.moving && g.drag.from == s || g.snap.frames > 0 && g.snap.to == s
+}
+
+// drawDrag draws the piece in the air, snapped to its drop square when it
+// has one, and any piece flying home.
+func (g *Game) drawDrag(screen *ebiten.Image) {
+	if s := g.snap; s.frames > 0 {
+		t := 1 - float64(s.frames)/snapFrames
+		tx, ty := g.renderSquare(s.to.X, s.to.Y)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(s.x+(float64(tx)-s.x)*t, s.y+(float64(ty)-s.y)*t)
+		screen.DrawImage(sprites[s.sprite], op)
+	}
+	p := g.board[g.drag.from.Y][g.drag.from.X]
+	if !g.drag.moving || p == nil {
+		return
+	}
+	x, y := g.drag.x-tileSize/2, g.drag.y-tileSize/2
+	if to, ok := g.dropSquare(); ok {
+		x, y = g.renderSquare(to.X, to.Y)
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	screen.DrawImage(sprites[p.SpriteID], op)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// updateDrop starts an unstaked 5-minute game, as the -fen flag does, from a
+// .fen or .pgn file dropped on the window. Files without either extension are
+// read as PGN if they open with a tag. A staked game in progress ignores
+// drops, so nobody can swap in a won position.
+func (g *Game) updateDrop() {
+	files := ebiten.DroppedFiles()
+	if files == nil || g.staked() {
+		return
+	}
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil || len(entries) == 0 {
+		return
+	}
+	name := entries[0].Name()
+	data, err := fs.ReadFile(files, name)
+	text := strings.TrimSpace(string(data))
+	ext := strings.ToLower(path.Ext(name))
+	pgn := ext == ".pgn" || ext != ".fen" && strings.HasPrefix(text, "[")
+
+	ng := newPositionGame()
+	if err == nil && pgn {
+		err = ng.LoadPGN(text)
+	} else if err == nil {
+		err = ng.LoadFEN(text)
+	}
+	if err != nil {
+		fmt.Printf("%s: %v\n", name, err)
+		note := "BAD FEN FILE"
+		if pgn {
+			note = "BAD PGN FILE"
+		}
+		if g.gameStarted {
+			g.currentDialog = note
+		} else {
+			g.menuNote = note
+		}
+		return
+	}
+	fmt.Println("loaded", name)
+	*g = *ng
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// NewEditorGame opens the board editor on the standard starting position.
+// Nothing is played or staked until the edit is finished.
+func NewEditorGame() *Game {
+	g := NewGame(0, 5)
+	g.editing = true
+	g.editSprite = -1
+	g.currentDialog = "TAB:side ENTER:play"
+	return g
+}
+
+// editSquare puts the palette piece on a square. Placing a piece on a square
+// that already holds the same piece takes it off again.
+func (g *Game) editSquare(x, y int) {
+	if p := g.board[y][x]; g.editSprite < 0 || (p != nil && p.SpriteID == g.editSprite) {
+		g.board[y][x] = nil
+		return
+	}
+	g.createPiece(PieceType(g.editSprite%6), Color(g.editSprite/6), x, y)
+}
+
+// clearEdit empties the board.
+func (g *Game) clearEdit() {
+	g.board = [8][8]*ChessPiece{}
+}
+
+// validatePosition checks that an edited or loaded position can be played:
+// one king each, no more than eight pawns a side and none on the first or last
+// rank, and the side that just moved not left in check.
+func (g *Game) validatePosition() error {
+	var kings, pawns [2]int
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			p := g.board[y][x]
+			if p == nil {
+				continue
+			}
+			switch p.Type {
+			case King:
+				kings[p.Color]++
+			case Pawn:
+				pawns[p.Color]++
+			}
+			if p.Type == Pawn && (y == 0 || y == 7) {
+				return fmt.Errorf("PAWN ON %s", toAlg(x, y))
+			}
+		}
+	}
+	if kings[White] != 1 {
+		return errors.New("NEED ONE WHITE KING")
+	}
+	if kings[Black] != 1 {
+		return errors.New("NEED ONE BLACK KING")
+	}
+	if pawns[White] > 8 {
+		return errors.New("TOO MANY WHITE PAWNS")
+	}
+	if pawns[Black] > 8 {
+		return errors.New("TOO MANY BLACK PAWNS")
+	}
+	if g.isInCheck(1 - g.activeColor) {
+		return errors.New("OFF-MOVE KING IN CHECK")
+	}
+	return nil
+}
+
+// editedFEN validates the edited position and describes it as FEN. Castling
+// is only kept for kings and rooks still on their standard home squares.
+func (g *Game) editedFEN() (string, error) {
+	if err := g.validatePosition(); err != nil {
+		return "", err
+	}
+	g.castling = [2][2]bool{}
+	for c, y := range [2]int{Black: 0, White: 7} {
+		if k := g.board[y][4]; k == nil || k.Type != King || k.Color != Color(c) {
+			continue
+		}
+		for side, rx := range g.rookFiles {
+			r := g.board[y][rx]
+			g.castling[c][side] = r != nil && r.Type == Rook && r.Color == Color(c)
+		}
+	}
+	g.epX, g.epY = -1, -1
+	return g.FEN(), nil
+}
+
+// finishEdit loads the edited position as a fresh game against Frank and
+// returns its FEN.
+func (g *Game) finishEdit() (string, error) {
+	fen, err := g.editedFEN()
+	if err != nil {
+		return "", err
+	}
+	if err := g.LoadFEN(fen); err != nil {
+		return "", err
+	}
+	g.editing = false
+	g.currentDialog = "Your setup, your funeral."
+	return fen, nil
+}
+
+// saveFEN writes fen to a file named for the current time in the working
+// directory, ready to be dropped back on the window, and returns its name.
+func saveFEN(fen string) (string, error) {
+	name := time.Now().Format("chess-20060102-150405.fen")
+	return name, os.WriteFile(name, []byte(fen+"\n"), 0o644)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// A saved edit loads back as the same position.
+func TestSaveFEN(t *testing.T) {
+	t.Chdir(t.TempDir())
+	g := NewEditorGame()
+	g.board[6][4], g.board[1][3] = nil, nil
+	fen, err := g.editedFEN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := saveFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(name, ".fen") {
+		t.Errorf("saved as %s, want a .fen file", name)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back := NewGame(0, 0)
+	if err := back.LoadFEN(string(data)); err != nil {
+		t.Fatal(err)
+	}
+	if back.FEN() != fen {
+		t.Errorf("loaded %s, saved %s", back.FEN(), fen)
+	}
+}
@@ -0,0 +1,869 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+type Color int
+
+const (
+	Black Color = iota
+	White
+)
+
+type PieceType int
+
+const (
+	Pawn PieceType = iota
+	Bishop
+	Rook
+	Knight
+	Queen
+	King
+)
+
+// Piece values for Frank's brain
+var pieceValues = map[PieceType]int{
+	Pawn: 1, Knight: 3, Bishop: 3, Rook: 5, Queen: 9, King: 100,
+}
+
+type ChessPiece struct {
+	Type     PieceType
+	Color    Color
+	SpriteID int
+}
+
+// Pos is a board square: X is the file (0 = a), Y the row (0 = rank 8).
+type Pos struct{ X, Y int }
+
+// Move is a move from one square to another. Promo is the piece a pawn
+// reaching the last rank becomes; Pawn, the zero value, leaves it to the
+// default, which is a queen, or asking the human player.
+type Move struct {
+	From, To Pos
+	Promo    PieceType
+}
+
+// fade is a captured piece fading out on its square. En passant needs it
+// because the pawn vanishes from a square the capturer never lands on.
+type fade struct {
+	x, y, sprite int
+	frames       int // left to show
+}
+
+const fadeFrames = 40
+
+// ticksPerSecond is how often Update runs, and so the unit of the clocks and
+// every frame count. Ebitengine holds Update to this rate whatever the
+// monitor's refresh rate, calling it more than once per drawn frame to catch
+// up when drawing falls behind.
+const ticksPerSecond = 60
+
+// slide is a moving piece gliding between squares. The board already holds
+// the finished move, so the mover is drawn in flight and anything it took
+// stays on the target square until it lands.
+type slide struct {
+	from, to Pos
+	sprite   int
+	captured int // SpriteID of the taken piece, or -1
+	frames   int // left to show
+}
+
+const slideFrames = 6
+
+// drag is a press on one of the player's pieces, which selected it the
+// usual way, turning into a drag once the pointer moves off a few pixels.
+type drag struct {
+	from           Pos
+	pressX, pressY int  // where the press landed, in canvas pixels
+	x, y           int  // where the pointer is now
+	active         bool // the button or finger is still down
+	moving         bool // it has moved far enough to be a drag
+}
+
+// snapBack is a piece dropped off any legal square flying home from where
+// it was let go.
+type snapBack struct {
+	x, y   float64 // where it was let go, in canvas pixels
+	to     Pos
+	sprite int
+	frames int // left to show
+}
+
+const snapFrames = 8
+
+// verboseLog adds the raw board coordinates, the resulting FEN and whether
+// the side to move is in check, mated or stalemated to every logged move, and
+// prints each finished game as PGN.
+var verboseLog bool
+
+// moveSound, when set, is called with the mover's color for every move made
+// in a real game. The window sets it; headless and search games stay silent.
+var moveSound func(c Color)
+
+type Game struct {
+	board                  [8][8]*ChessPiece
+	selectedX, selectedY   int
+	padCursor              Pos  // the square the on-screen pad is on
+	pendingX, pendingY     int  // destination awaiting a confirming click
+	premoveFrom, premoveTo Pos  // queued while Frank thinks; X is -1 when unset
+	typingMove             bool // typing a move into the HUD
+	moveEntry              string
+	activeColor            Color
+	hustlerName            string
+	currentDialog          string
+	whiteTime, blackTime   float64
+	gameOver               bool
+	gameStarted            bool
+	wager                  int
+	initialMins            int
+	startTimes             [2]float64 // each side's clock at the start, after odds and berserk
+	rng                    *rand.Rand
+	epX, epY               int
+	winner                 int
+	moveCount              int
+	frankThinkTime         int
+	thinkLimit             int // frames Frank takes over this move, set on its first frame
+	promoting              bool
+	promX, promY           int
+	history                []MoveRecord
+	startFEN               string // where history begins
+	evals                  []int  // Frank's search score after each of his moves, for the player
+	takebackTo             *Game  // the position before the player's last move, while it can be taken back
+	takebacks              int    // takebacks bought this game
+	rewindOffer            bool   // easy assist: Frank just punished a blunder, and it can be taken back free
+	lastFrom, lastTo       Pos
+	epFade                 fade
+	slide                  slide
+	drag                   drag
+	snap                   snapBack
+	lastCue                cue // the latest cue, flashing while cueFrames lasts
+	cueFrames              int
+	flipped                bool           // Black at the bottom of the board
+	halfmoveClock          int            // half-moves since the last capture or pawn move
+	repetitions            map[string]int // times each positionKey has occurred
+	positions              []string       // positionKey of the start and of the position after each move in history
+	reviewBoard            *Game          // the position on the review screen, while it is open
+	postMortem             *postMortem    // the finished game under analysis, while it is open
+	reviewIdx, reviewPly   int            // the recentGames entry and position shown
+	endReason              string         // why the game ended, once gameOver
+	showHistory            bool
+	showThreats            bool // tint squares Frank attacks, on the player's turn
+	blindfold              bool // hide the pieces, for playing from memory
+	hotseat                bool // two players taking turns at one board, with no hustler
+	autoQueen              bool // the player's next promotion goes straight to a queen, without asking
+	enteringName           bool // typing a new profile name on the menu
+	nameEntry              string
+	confirmReset           bool   // R was pressed once on the menu
+	menuNote               string // shown in place of the menu's key hints until a key is pressed
+	confirmQuit            bool   // the window was closed mid-game; asking before leaving
+	showSettings           bool
+	menuShown              menuScreen // the menu menuCursor belongs to
+	menuCursor             int        // highlighted row, moved by the arrow keys
+	turnClock              float64    // mover's clock when the current turn began
+	increment              float64    // frames credited to the mover after each move
+	bronstein              bool       // increment is a delay: capped at the move's time
+	betOffered             bool       // Frank has made his side-bet offer this game
+	betPending             bool       // ... and is waiting for an answer
+	sideBet                int        // accepted side bet, paid on a mate before betDeadline
+	betDeadline            int
+	chess960               bool         // Fischer Random back rank
+	losAlamos              bool         // 6x6 Los Alamos chess on the middle of the board
+	armageddon             bool         // White has more time, but a draw is a win for Black
+	odds                   int          // index into materialOdds: the piece Black starts without
+	berserk                bool         // the player started on half their clock for a bigger payout
+	rated                  bool         // no assists, and the result goes on the rated record
+	payoutRate             int          // percent of the usual payout a win collects
+	budget                 searchBudget // limits on Frank's search, from the settings
+	countdown              int          // frames left of the countdown before play starts
+	lastSearch             searchStats  // what his latest search did
+	rookFiles              [2]int       // starting files of the queen- and king-side rooks
+	castling               [2][2]bool   // castling rights by Color, then 0 for the rookFiles[0] side and 1 for the other
+	personality            *Personality // Frank's style; nil means the hustler
+	stakesDepth            int          // plies the stakes add to the opponent's depth
+	opponent               *Opponent    // who Frank is this game; nil means Frank himself
+	puzzle                 *Puzzle      // set in puzzle mode
+	puzzleIdx              int
+	puzzleStep             int  // index into the solution of the next move
+	puzzleOnLine           bool // player has followed the listed solution
+	puzzleDone             bool
+	puzzleSolved           bool
+	puzzleAnswer           string    // listed solution in SAN, shown after a miss
+	lesson                 bool      // in a mating lesson
+	lessonIdx              int       // index into lessons
+	lessonDone             bool      // the lesson ended in mate or stalemate
+	lessonGood             []Move    // the player's best moves, as coachLesson sees it
+	lessonStalemates       []Move    // the player's moves that stalemate
+	demo                   bool      // self-play game: no logging, no prompts
+	demoGame               *Game     // attract-mode game running behind the menu
+	tutorial               *tutorial // first-run tutorial over the menu, or nil
+	editing                bool      // setting up a position in the board editor
+	editSprite             int       // palette pick: a piece SpriteID, or -1 to erase
+}
+
+func NewGame(wager int, minutes int) *Game {
+	g := &Game{
+		selectedX: -1, selectedY: -1, epX: -1, epY: -1,
+		pendingX: -1, pendingY: -1,
+		premoveFrom: Pos{-1, -1}, premoveTo: Pos{-1, -1},
+		padCursor:     Pos{4, 6}, // e2
+		activeColor:   White,
+		hustlerName:   "4-Move-Frank",
+		currentDialog: "Eyes on the board, kid.",
+		whiteTime:     float64(minutes * 60 * ticksPerSecond),
+		blackTime:     float64(minutes * 60 * ticksPerSecond),
+		turnClock:     float64(minutes * 60 * ticksPerSecond),
+		startTimes:    [2]float64{float64(minutes * 60 * ticksPerSecond), float64(minutes * 60 * ticksPerSecond)},
+		wager:         wager,
+		payoutRate:    100,
+		gameStarted:   true,
+		initialMins:   minutes,
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		winner:        -1,
+		repetitions:   map[string]int{},
+	}
+	g.setupBoard()
+	g.startFEN = g.FEN()
+	g.recordPosition()
+	return g
+}
+
+// NewChess960Game is NewGame with a random Fischer Random back rank.
+func NewChess960Game(wager int, minutes int) *Game {
+	g := NewGame(wager, minutes)
+	g.chess960 = true
+	g.rebuildBoard()
+	return g
+}
+
+// rebuildBoard sets the pieces up again after a change to how setupBoard
+// lays them out, forgetting the position it replaces.
+func (g *Game) rebuildBoard() {
+	g.board = [8][8]*ChessPiece{}
+	g.setupBoard()
+	g.startFEN = g.FEN()
+	g.repetitions, g.positions = map[string]int{}, nil
+	g.recordPosition()
+}
+
+func (g *Game) setupBoard() {
+	if g.losAlamos {
+		g.setupLosAlamos()
+		g.removeOddsPiece()
+		return
+	}
+	layout := []PieceType{Rook, Knight, Bishop, Queen, King, Bishop, Knight, Rook}
+	if g.chess960 {
+		layout = g.chess960Layout()
+	}
+	g.rookFiles = [2]int{-1, -1}
+	g.castling = [2][2]bool{{true, true}, {true, true}}
+	for i := 0; i < 8; i++ {
+		if layout[i] == Rook {
+			if g.rookFiles[0] == -1 {
+				g.rookFiles[0] = i
+			} else {
+				g.rookFiles[1] = i
+			}
+		}
+		g.createPiece(layout[i], Black, i, 0)
+		g.createPiece(Pawn, Black, i, 1)
+		g.createPiece(Pawn, White, i, 6)
+		g.createPiece(layout[i], White, i, 7)
+	}
+	g.removeOddsPiece()
+}
+
+// chess960Layout draws one of the 960 legal back ranks: bishops on opposite
+// colors and the king somewhere between the two rooks.
+func (g *Game) chess960Layout() []PieceType {
+	layout := make([]PieceType, 8)
+	free := make([]bool, 8)
+	for i := range free {
+		free[i] = true
+	}
+	put := func(t PieceType, x int) { layout[x], free[x] = t, false }
+	// nth empty file, counting from the a-file
+	nth := func(n int) int {
+		for x := 0; x < 8; x++ {
+			if free[x] {
+				if n == 0 {
+					return x
+				}
+				n--
+			}
+		}
+		return -1
+	}
+	put(Bishop, 2*g.rng.Intn(4))
+	put(Bishop, 2*g.rng.Intn(4)+1)
+	put(Queen, nth(g.rng.Intn(6)))
+	put(Knight, nth(g.rng.Intn(5)))
+	put(Knight, nth(g.rng.Intn(4)))
+	put(Rook, nth(0))
+	put(King, nth(0))
+	put(Rook, nth(0))
+	return layout
+}
+
+func (g *Game) createPiece(t PieceType, c Color, x, y int) {
+	g.board[y][x] = newPiece(t, c)
+}
+
+func newPiece(t PieceType, c Color) *ChessPiece {
+	return &ChessPiece{Type: t, Color: c, SpriteID: spriteID(t, c)}
+}
+
+// spriteID is the sheet index of a piece: Black's pieces in PieceType order,
+// then White's.
+func spriteID(t PieceType, c Color) int {
+	if c == White {
+		return int(t) + 6
+	}
+	return int(t)
+}
+
+func toAlg(x, y int) string { return fmt.Sprintf("%c%d", 'a'+x, 8-y) }
+func pName(p *ChessPiece) string {
+	return []string{"Pawn", "Bishop", "Rook", "Knight", "Queen", "King"}[p.Type]
+}
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// isPathClear reports whether every square strictly between from and to, on
+// a straight or diagonal line, is empty.
+func (g *Game) isPathClear(from, to Pos) bool {
+	fx, fy, tx, ty := from.X, from.Y, to.X, to.Y
+	dx, dy := tx-fx, ty-fy
+	sx, sy := 0, 0
+	if dx != 0 {
+		sx = dx / abs(dx)
+	}
+	if dy != 0 {
+		sy = dy / abs(dy)
+	}
+	cx, cy := fx+sx, fy+sy
+	for cx != tx || cy != ty {
+		if g.board[cy][cx] != nil {
+			return false
+		}
+		cx += sx
+		cy += sy
+	}
+	return true
+}
+
+// isMoveLegal reports whether piece p may make move m by the way it moves,
+// ignoring whether that leaves its own king in check; see isMoveSafe.
+func (g *Game) isMoveLegal(p *ChessPiece, m Move) bool {
+	fx, fy, tx, ty := m.From.X, m.From.Y, m.To.X, m.To.Y
+	if !g.onBoard(tx, ty) {
+		return false
+	}
+	if rx := g.castleRookFile(p, m); rx >= 0 {
+		return g.canCastle(p, fx, fy, rx)
+	}
+	target := g.board[ty][tx]
+	if target != nil && target.Color == p.Color {
+		return false
+	}
+	dx, dy := abs(tx-fx), abs(ty-fy)
+
+	switch p.Type {
+	case Knight:
+		return (dx == 2 && dy == 1) || (dx == 1 && dy == 2)
+	case Rook:
+		return (fx == tx || fy == ty) && g.isPathClear(m.From, m.To)
+	case Bishop:
+		return dx == dy && g.isPathClear(m.From, m.To)
+	case Queen:
+		return (dx == dy || fx == tx || fy == ty) && g.isPathClear(m.From, m.To)
+	case King:
+		return dx <= 1 && dy <= 1
+	case Pawn:
+		dir := -1
+		if p.Color == Black {
+			dir = 1
+		}
+		if fx == tx && ty == fy+dir && target == nil {
+			return true
+		}
+		if fx == tx && ty == fy+2*dir && fy == (map[Color]int{White: 6, Black: 1}[p.Color]) && target == nil && g.isPathClear(m.From, m.To) {
+			return true
+		}
+		if dx == 1 && ty == fy+dir {
+			if target != nil || (tx == g.epX && ty == g.epY) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// castleRookFile returns the file of the rook the king castles with on this
+// move, or -1 if it isn't a castling move. Standard chess castles by moving the
+// king two squares; in Chess960 the king is dropped onto its own rook instead,
+// since its destination may be its own square or a plain one-step move.
+func (g *Game) castleRookFile(p *ChessPiece, m Move) int {
+	fx, fy, tx, ty := m.From.X, m.From.Y, m.To.X, m.To.Y
+	if p.Type != King || fy != ty {
+		return -1
+	}
+	if g.chess960 {
+		t := g.board[ty][tx]
+		if t != nil && t.Color == p.Color && t.Type == Rook && (tx == g.rookFiles[0] || tx == g.rookFiles[1]) {
+			return tx
+		}
+		return -1
+	}
+	if abs(tx-fx) != 2 {
+		return -1
+	}
+	if tx > fx {
+		return g.rookFiles[1]
+	}
+	return g.rookFiles[0]
+}
+
+// castleTargets gives the king's and rook's landing files when castling with
+// the rook on file rx: the g- and f-files king-side, c- and d-files queen-side.
+func castleTargets(kx, rx int) (int, int) {
+	if rx > kx {
+		return 6, 5
+	}
+	return 2, 3
+}
+
+// loseCastlingRights clears the rights move m by p gives up: both of p's once
+// its king moves, and one whenever a rook's home square is moved from or onto,
+// which covers the rook leaving and the rook being captured there.
+func (g *Game) loseCastlingRights(p *ChessPiece, m Move) {
+	if p.Type == King {
+		g.castling[p.Color] = [2]bool{}
+	}
+	for c, y := range [2]int{Black: 0, White: 7} {
+		for side, rx := range g.rookFiles {
+			if (m.From == Pos{rx, y} || m.To == Pos{rx, y}) {
+				g.castling[c][side] = false
+			}
+		}
+	}
+}
+
+func (g *Game) canCastle(king *ChessPiece, kx, ky, rx int) bool {
+	side := 0
+	if rx == g.rookFiles[1] {
+		side = 1
+	}
+	rook := g.board[ky][rx]
+	if !g.castling[king.Color][side] || rook == nil || rook.Type != Rook || rook.Color != king.Color {
+		return false
+	}
+	if g.isInCheck(king.Color) {
+		return false
+	}
+	kdest, rdest := castleTargets(kx, rx)
+	lo, hi := min(kx, rx, kdest, rdest), max(kx, rx, kdest, rdest)
+	for x := lo; x <= hi; x++ {
+		if x != kx && x != rx && g.board[ky][x] != nil {
+			return false
+		}
+	}
+	// The king may not pass through or land on an attacked square.
+	for x := min(kx, kdest); x <= max(kx, kdest); x++ {
+		if x != kx && len(g.attackersOf(x, ky, 1-king.Color)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isMoveSafe reports whether a move isMoveLegal allows also keeps the mover's
+// king out of check. Castling was already vetted square by square. A move
+// onto a king is never safe: isMoveLegal allows it so attacks on the king can
+// be found, but kings are mated, not taken, and every position has both.
+func (g *Game) isMoveSafe(m Move) bool {
+	fx, fy, tx, ty := m.From.X, m.From.Y, m.To.X, m.To.Y
+	p := g.board[fy][fx]
+	if g.castleRookFile(p, m) >= 0 {
+		return true
+	}
+	orig := g.board[ty][tx]
+	if orig != nil && orig.Type == King {
+		return false
+	}
+	g.board[ty][tx], g.board[fy][fx] = p, nil
+	safe := !g.isInCheck(p.Color)
+	g.board[fy][fx], g.board[ty][tx] = p, orig
+	return safe
+}
+
+func (g *Game) isInCheck(c Color) bool {
+	kx, ky := -1, -1
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if p := g.board[y][x]; p != nil && p.Type == King && p.Color == c {
+				kx, ky = x, y
+			}
+		}
+	}
+	if kx == -1 {
+		// Positions are checked for a king each on the way in and no
+		// legal move takes one, so this is a bug; carrying on would
+		// quietly call a kingless side safe.
+		panic(fmt.Sprintf("isInCheck: no king for color %d in %s", c, g.FEN()))
+	}
+	return g.isSquareAttacked(kx, ky, 1-c)
+}
+
+func (g *Game) isSquareAttacked(x, y int, attackerColor Color) bool {
+	for fy := 0; fy < 8; fy++ {
+		for fx := 0; fx < 8; fx++ {
+			p := g.board[fy][fx]
+			if p != nil && p.Color == attackerColor {
+				if g.isMoveLegal(p, Move{From: Pos{fx, fy}, To: Pos{x, y}}) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// attackersOf lists every piece of color by that attacks (x, y). Whatever sits
+// on the square is swapped for an enemy stand-in while scanning, so this also
+// counts defenders of a piece and pawn attacks on empty squares.
+func (g *Game) attackersOf(x, y int, by Color) []Pos {
+	orig := g.board[y][x]
+	g.board[y][x] = &ChessPiece{Type: Pawn, Color: 1 - by}
+	defer func() { g.board[y][x] = orig }()
+
+	var out []Pos
+	for fy := 0; fy < 8; fy++ {
+		for fx := 0; fx < 8; fx++ {
+			p := g.board[fy][fx]
+			if p != nil && p.Color == by && g.isMoveLegal(p, Move{From: Pos{fx, fy}, To: Pos{x, y}}) {
+				out = append(out, Pos{fx, fy})
+			}
+		}
+	}
+	return out
+}
+
+// see is the static exchange evaluation of the capture m:
+// the net material the mover ends up with if both sides keep recapturing on
+// the square with their cheapest attacker and may stop whenever it pays.
+// Pins are ignored; x-rays fall out of re-scanning after every capture.
+func (g *Game) see(m Move) int {
+	fx, fy, tx, ty := m.From.X, m.From.Y, m.To.X, m.To.Y
+	p, target := g.board[fy][fx], g.board[ty][tx]
+	if p == nil || target == nil {
+		return 0
+	}
+	type taken struct {
+		pos   Pos
+		piece *ChessPiece
+	}
+	lifted := []taken{{Pos{fx, fy}, p}}
+	g.board[fy][fx], g.board[ty][tx] = nil, p
+
+	gain := []int{pieceValues[target.Type]}
+	onSquare, side := p, 1-p.Color
+	for {
+		from, cheapest := Pos{-1, -1}, 0
+		for _, a := range g.attackersOf(tx, ty, side) {
+			if v := pieceValues[g.board[a.Y][a.X].Type]; from.X == -1 || v < cheapest {
+				from, cheapest = a, v
+			}
+		}
+		if from.X == -1 {
+			break
+		}
+		gain = append(gain, pieceValues[onSquare.Type]-gain[len(gain)-1])
+		onSquare = g.board[from.Y][from.X]
+		lifted = append(lifted, taken{from, onSquare})
+		g.board[from.Y][from.X], g.board[ty][tx] = nil, onSquare
+		side = 1 - side
+	}
+
+	for _, t := range lifted {
+		g.board[t.pos.Y][t.pos.X] = t.piece
+	}
+	g.board[ty][tx] = target
+
+	// Walk back up the sequence: each side only recaptures when it helps.
+	for i := len(gain) - 1; i > 0; i-- {
+		if gain[i] > -gain[i-1] {
+			gain[i-1] = -gain[i]
+		}
+	}
+	return gain[0]
+}
+
+// hanging lists c's pieces, king aside, that some enemy piece can capture
+// at a profit by static exchange: attacked and not defended well enough.
+func (g *Game) hanging(c Color) []Pos {
+	var out []Pos
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if p := g.board[y][x]; p == nil || p.Color != c || p.Type == King {
+				continue
+			}
+			for _, a := range g.attackersOf(x, y, 1-c) {
+				if g.see(Move{From: a, To: Pos{x, y}}) > 0 {
+					out = append(out, Pos{x, y})
+					break
+				}
+			}
+		}
+	}
+	return out
+}
+
+// LegalMoves lists every legal move for c, castling and en passant
+// included. Promotions are listed once, with Promo left to the default.
+func (g *Game) LegalMoves(c Color) []Move {
+	var moves []Move
+	for fy := 0; fy < 8; fy++ {
+		for fx := 0; fx < 8; fx++ {
+			p := g.board[fy][fx]
+			if p == nil || p.Color != c {
+				continue
+			}
+			for ty := 0; ty < 8; ty++ {
+				for tx := 0; tx < 8; tx++ {
+					m := Move{From: Pos{fx, fy}, To: Pos{tx, ty}}
+					if g.isMoveLegal(p, m) && g.isMoveSafe(m) {
+						moves = append(moves, m)
+					}
+				}
+			}
+		}
+	}
+	return moves
+}
+
+func (g *Game) hasLegalMoves(c Color) bool {
+	return len(g.LegalMoves(c)) > 0
+}
+
+// material is c's piece values added up, king excluded.
+func (g *Game) material(c Color) int {
+	n := 0
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if p := g.board[y][x]; p != nil && p.Color == c && p.Type != King {
+				n += pieceValues[p.Type]
+			}
+		}
+	}
+	return n
+}
+
+// executeMove plays m, which must be legal, and passes the turn unless the
+// human still has to pick a promotion piece.
+func (g *Game) executeMove(m Move) {
+	fx, fy, tx, ty := m.From.X, m.From.Y, m.To.X, m.To.Y
+	p := g.board[fy][fx]
+	if !g.demo {
+		if p.Color == White {
+			g.saveTakeback()
+		}
+		fmt.Printf("[%d] %s: %s -> %s\n", p.Color, pName(p), toAlg(fx, fy), toAlg(tx, ty))
+		if verboseLog {
+			fmt.Printf("    board (%d,%d) -> (%d,%d)\n", fx, fy, tx, ty)
+		}
+		if moveSound != nil {
+			moveSound(p.Color)
+		}
+	}
+	g.lastFrom, g.lastTo = Pos{fx, fy}, Pos{tx, ty}
+	rec := MoveRecord{SAN: g.san(m), Elapsed: (g.turnClock - g.clock(p.Color)) / ticksPerSecond}
+
+	mv := slide{from: Pos{fx, fy}, to: Pos{tx, ty}, sprite: p.SpriteID, captured: -1, frames: slideFrames}
+	if q := g.board[ty][tx]; q != nil && q.Color != p.Color {
+		mv.captured = q.SpriteID
+	}
+	g.halfmoveClock++
+	if mv.captured >= 0 || p.Type == Pawn {
+		g.halfmoveClock = 0
+	}
+	g.loseCastlingRights(p, m)
+	castled := false
+	if rx := g.castleRookFile(p, m); rx >= 0 {
+		kx, rtx := castleTargets(fx, rx)
+		mv.to = Pos{kx, fy}
+		rook := g.board[fy][rx]
+		g.board[fy][fx], g.board[fy][rx] = nil, nil
+		g.board[fy][kx], g.board[fy][rtx] = p, rook
+		castled = true
+	}
+
+	if p.Type == Pawn && tx == g.epX && ty == g.epY {
+		g.epFade = fade{x: tx, y: fy, sprite: g.board[fy][tx].SpriteID, frames: fadeFrames}
+		g.board[fy][tx] = nil
+	}
+	g.epX, g.epY = -1, -1
+	if p.Type == Pawn && abs(ty-fy) == 2 {
+		g.epX, g.epY = fx, fy+(ty-fy)/2
+	}
+
+	if !castled {
+		g.board[ty][tx], g.board[fy][fx] = p, nil
+	}
+	g.slide = mv
+
+	if p.Type == Pawn && ty == g.lastRank(p.Color) {
+		if (p.Color == White || g.hotseat) && !g.demo && !g.autoQueen && m.Promo == Pawn {
+			g.promoting = true
+			g.promX, g.promY = tx, ty
+		} else {
+			promo := m.Promo
+			if promo == Pawn {
+				promo = Queen
+			}
+			p.Type = promo
+			p.SpriteID = spriteID(promo, p.Color)
+			rec.SAN += "=" + pieceLetters[promo]
+		}
+	}
+	g.history = append(g.history, rec)
+	if !g.promoting {
+		g.endTurn()
+	}
+	g.moveCount++
+	g.frankThinkTime = 0
+}
+
+// clone is a deep copy of the game for trying moves out. The copy is marked
+// as a demo game so it neither logs nor stops for promotion choices, and
+// doesn't track repetitions.
+func (g *Game) clone() *Game {
+	c := *g
+	for y := range c.board {
+		for x, p := range c.board[y] {
+			if p != nil {
+				cp := *p
+				c.board[y][x] = &cp
+			}
+		}
+	}
+	c.history = append([]MoveRecord(nil), g.history...)
+	c.demo, c.demoGame, c.repetitions = true, nil, nil
+	return &c
+}
+
+// promote finishes a pending human promotion with the chosen piece. Los
+// Alamos chess has no bishops to promote to, so that choice is ignored.
+func (g *Game) promote(t PieceType) {
+	if t == Bishop && g.losAlamos {
+		return
+	}
+	p := g.board[g.promY][g.promX]
+	p.Type, p.SpriteID = t, spriteID(t, p.Color)
+	g.promoting = false
+	g.history[len(g.history)-1].SAN += "=" + pieceLetters[t]
+	g.endTurn()
+}
+
+// endTurn hands the move to the other side once a move is fully made,
+// first crediting the mover's clock with any increment. A Bronstein delay
+// gives back no more than the move actually took.
+func (g *Game) endTurn() {
+	if g.increment > 0 {
+		add := g.increment
+		if g.bronstein {
+			add = min(add, g.turnClock-g.clock(g.activeColor))
+		}
+		if g.activeColor == White {
+			g.whiteTime += add
+		} else {
+			g.blackTime += add
+		}
+	}
+	g.activeColor = 1 - g.activeColor
+	g.history[len(g.history)-1].SAN += g.checkSuffix()
+	g.signalMove()
+	g.turnClock = g.clock(g.activeColor)
+	g.recordPosition()
+	if verboseLog && !g.demo {
+		fmt.Printf("    %s %s\n", g.FEN(), g.positionState())
+	}
+}
+
+// positionState names the state of the side to move for the verbose log.
+func (g *Game) positionState() string {
+	check, moves := g.isInCheck(g.activeColor), g.hasLegalMoves(g.activeColor)
+	switch {
+	case check && !moves:
+		return "checkmate"
+	case !moves:
+		return "stalemate"
+	case check:
+		return "check"
+	}
+	return "-"
+}
+
+func (g *Game) clock(c Color) float64 {
+	if c == White {
+		return g.whiteTime
+	}
+	return g.blackTime
+}
+
+// humanSide reports whether c is played from the keyboard and mouse: both
+// sides in hot seat, White in every other game, and neither in a demo.
+func (g *Game) humanSide(c Color) bool {
+	return !g.demo && (g.hotseat || c == White)
+}
+
+// playerMove is the one way input moves a piece. Clicks, typed moves and
+// pre-moves all come through it, so none of them can move out of turn: it
+// plays m and reports true only while the game is on, no promotion is
+// waiting, the side to move is a human's, and m is a legal move of one of
+// that side's pieces.
+func (g *Game) playerMove(m Move) bool {
+	if g.gameOver || g.promoting || !g.humanSide(g.activeColor) {
+		return false
+	}
+	p := g.board[m.From.Y][m.From.X]
+	if p == nil || p.Color != g.activeColor || !g.isMoveLegal(p, m) || !g.isMoveSafe(m) {
+		return false
+	}
+	g.executeMove(m)
+	return true
+}
+
+// queuePremove takes a board click made while Frank is thinking. The first
+// click picks one of the player's pieces, the second its destination; any
+// click on a queued pre-move, or on the picked piece again, cancels it.
+func (g *Game) queuePremove(x, y int) {
+	from, to := g.premoveFrom, g.premoveTo
+	g.premoveFrom, g.premoveTo = Pos{-1, -1}, Pos{-1, -1}
+	if from.X == -1 {
+		if p := g.board[y][x]; p != nil && p.Color == White {
+			g.premoveFrom = Pos{x, y}
+		}
+		return
+	}
+	if to.X == -1 && from != (Pos{x, y}) {
+		g.premoveFrom, g.premoveTo = from, Pos{x, y}
+	}
+}
+
+// playPremove makes the queued pre-move once it is the player's turn, if the
+// position still allows it, and drops it either way.
+func (g *Game) playPremove() {
+	from, to := g.premoveFrom, g.premoveTo
+	g.premoveFrom, g.premoveTo = Pos{-1, -1}, Pos{-1, -1}
+	if to.X != -1 {
+		g.playerMove(Move{From: from, To: to})
+	}
+}
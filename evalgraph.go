@@ -0,0 +1,61 @@
+package main
+
+import (
+	"image/color"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// The evaluation graph is a panel across the top of the board, above the
+// game-over box.
+const (
+	graphX, graphY = 8, 4
+	graphW, graphH = 144, 42
+	graphClamp     = 500 // centipawns at the panel's top and bottom edges
+)
+
+// graphEval is evals[i] clamped to the panel, so a found mate doesn't
+// flatten the rest of the game.
+func (g *Game) graphEval(i int) int {
+	return max(-graphClamp, min(graphClamp, g.evals[i]))
+}
+
+// biggestSwings returns the indexes into evals of the k moves after which the
+// evaluation changed most, ignoring moves that changed nothing.
+func (g *Game) biggestSwings(k int) []int {
+	var idx []int
+	for i := 1; i < len(g.evals); i++ {
+		if g.graphEval(i) != g.graphEval(i-1) {
+			idx = append(idx, i)
+		}
+	}
+	swing := func(i int) int { return abs(g.graphEval(i) - g.graphEval(i-1)) }
+	sort.SliceStable(idx, func(a, b int) bool { return swing(idx[a]) > swing(idx[b]) })
+	return idx[:min(k, len(idx))]
+}
+
+// drawEvalGraph plots Frank's score after each of his moves across the game,
+// the player's advantage upwards, with the three biggest swings marked.
+func (g *Game) drawEvalGraph(screen *ebiten.Image) {
+	n := len(g.evals)
+	if n < 2 {
+		return
+	}
+	vector.FillRect(screen, graphX, graphY, graphW, graphH, color.RGBA{0, 0, 0, 220}, false)
+	mid := float32(graphY + graphH/2)
+	vector.StrokeLine(screen, graphX, mid, graphX+graphW, mid, 1, color.RGBA{80, 80, 80, 255}, false)
+	pt := func(i int) (float32, float32) {
+		return graphX + float32(i)*graphW/float32(n-1), mid - float32(g.graphEval(i))*(graphH/2-2)/graphClamp
+	}
+	for _, i := range g.biggestSwings(3) {
+		x, _ := pt(i)
+		vector.StrokeLine(screen, x, graphY, x, graphY+graphH, 1, color.RGBA{255, 50, 50, 255}, false)
+	}
+	for i := 1; i < n; i++ {
+		x0, y0 := pt(i - 1)
+		x1, y1 := pt(i)
+		vector.StrokeLine(screen, x0, y0, x1, y1, 1, color.RGBA{0, 255, 150, 255}, false)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FEN piece letters, indexed by PieceType; White's are upper case.
+const fenLetters = "pbrnqk"
+
+// LoadFEN replaces the current position with the one fen describes. Only the
+// placement and side-to-move fields are required; a missing castling field
+// means nobody can castle. Positions that couldn't arise in a game, such as a
+// side without a king, are rejected and leave the game untouched. A loaded
+// position is standard chess on the full board, whatever variant the game
+// was.
+func (g *Game) LoadFEN(fen string) error {
+	f := strings.Fields(fen)
+	if len(f) < 2 {
+		return fmt.Errorf("fen: need at least placement and side to move, got %q", fen)
+	}
+	rows := strings.Split(f[0], "/")
+	if len(rows) != 8 {
+		return fmt.Errorf("fen: %d ranks, want 8", len(rows))
+	}
+	var board [8][8]*ChessPiece
+	for y, row := range rows {
+		x := 0
+		for _, r := range row {
+			if r >= '1' && r <= '8' {
+				x += int(r - '0')
+				continue
+			}
+			t := strings.IndexRune(fenLetters, unicode.ToLower(r))
+			if t < 0 {
+				return fmt.Errorf("fen: bad piece %q", r)
+			}
+			if x > 7 {
+				return fmt.Errorf("fen: rank %d is too long", 8-y)
+			}
+			c := Black
+			if unicode.IsUpper(r) {
+				c = White
+			}
+			board[y][x] = newPiece(PieceType(t), c)
+			x++
+		}
+		if x != 8 {
+			return fmt.Errorf("fen: rank %d has %d files, want 8", 8-y, x)
+		}
+	}
+
+	var active Color
+	switch f[1] {
+	case "w":
+		active = White
+	case "b":
+		active = Black
+	default:
+		return fmt.Errorf("fen: bad side to move %q", f[1])
+	}
+
+	var castling [2][2]bool
+	if len(f) > 2 && f[2] != "-" {
+		for _, r := range f[2] {
+			c, y, rx, side := White, 7, 7, 1
+			if unicode.IsLower(r) {
+				c, y = Black, 0
+			}
+			switch unicode.ToLower(r) {
+			case 'k':
+			case 'q':
+				rx, side = 0, 0
+			default:
+				return fmt.Errorf("fen: bad castling right %q", r)
+			}
+			king, rook := board[y][4], board[y][rx]
+			if king == nil || king.Type != King || king.Color != c || rook == nil || rook.Type != Rook || rook.Color != c {
+				return fmt.Errorf("fen: castling right %q without king and rook at home", r)
+			}
+			castling[c][side] = true
+		}
+	}
+
+	epX, epY := -1, -1
+	if len(f) > 3 && f[3] != "-" {
+		if len(f[3]) != 2 || f[3][0] < 'a' || f[3][0] > 'h' || (f[3][1] != '3' && f[3][1] != '6') {
+			return fmt.Errorf("fen: bad en passant square %q", f[3])
+		}
+		epX, epY = int(f[3][0]-'a'), 8-int(f[3][1]-'0')
+	}
+
+	halfmove := 0
+	if len(f) > 4 {
+		n, err := strconv.Atoi(f[4])
+		if err != nil || n < 0 {
+			return fmt.Errorf("fen: bad halfmove clock %q", f[4])
+		}
+		halfmove = n
+	}
+
+	fullmove := 1
+	if len(f) > 5 {
+		n, err := strconv.Atoi(f[5])
+		if err != nil || n < 1 {
+			return fmt.Errorf("fen: bad move number %q", f[5])
+		}
+		fullmove = n
+	}
+
+	probe := &Game{board: board, activeColor: active, epX: -1, epY: -1, rookFiles: [2]int{0, 7}}
+	if err := probe.validatePosition(); err != nil {
+		return fmt.Errorf("fen: illegal position: %s", strings.ToLower(err.Error()))
+	}
+
+	g.board = board
+	g.activeColor = active
+	g.epX, g.epY = epX, epY
+	g.chess960, g.losAlamos, g.rookFiles, g.castling = false, false, [2]int{0, 7}, castling
+	g.moveCount = (fullmove-1)*2 + int(1-active)
+	g.halfmoveClock = halfmove
+	g.selectedX, g.selectedY = -1, -1
+	g.promoting, g.gameOver, g.winner = false, false, -1
+	g.history = nil
+	g.startFEN = g.FEN()
+	g.turnClock = g.clock(active)
+	g.repetitions, g.positions = map[string]int{}, nil
+	g.recordPosition()
+	return nil
+}
+
+// FEN describes the current position.
+func (g *Game) FEN() string {
+	var b strings.Builder
+	for y := 0; y < 8; y++ {
+		empty := 0
+		for x := 0; x < 8; x++ {
+			p := g.board[y][x]
+			if p == nil {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			l := fenLetters[p.Type]
+			if p.Color == White {
+				l -= 'a' - 'A'
+			}
+			b.WriteByte(l)
+		}
+		if empty > 0 {
+			b.WriteByte(byte('0' + empty))
+		}
+		if y < 7 {
+			b.WriteByte('/')
+		}
+	}
+
+	side := "w"
+	if g.activeColor == Black {
+		side = "b"
+	}
+	castling := ""
+	for _, c := range []struct {
+		letter byte
+		color  Color
+		side   int
+	}{{'K', White, 1}, {'Q', White, 0}, {'k', Black, 1}, {'q', Black, 0}} {
+		if g.castling[c.color][c.side] {
+			castling += string(c.letter)
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	ep := "-"
+	if g.epX >= 0 {
+		ep = toAlg(g.epX, g.epY)
+	}
+	return fmt.Sprintf("%s %s %s %s %d %d", b.String(), side, castling, ep, g.halfmoveClock, g.moveCount/2+1)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadFENRejectsIllegalPositions(t *testing.T) {
+	tests := []struct {
+		name, fen, want string
+	}{
+		{"no white king", "4k3/8/8/8/8/8/8/8 w - - 0 1", "one white king"},
+		{"no black king", "8/8/8/8/8/8/8/4K3 w - - 0 1", "one black king"},
+		{"two white kings", "4k3/8/8/8/8/8/8/3KK3 w - - 0 1", "one white king"},
+		{"two black kings", "3kk3/8/8/8/8/8/8/4K3 b - - 0 1", "one black king"},
+		{"pawn on eighth rank", "P3k3/8/8/8/8/8/8/4K3 w - - 0 1", "pawn on a8"},
+		{"pawn on first rank", "4k3/8/8/8/8/8/8/4K2p b - - 0 1", "pawn on h1"},
+		{"nine white pawns", "4k3/8/8/8/8/P7/PPPPPPPP/4K3 w - - 0 1", "too many white pawns"},
+		{"nine black pawns", "4k3/pppppppp/p7/8/8/8/8/4K3 b - - 0 1", "too many black pawns"},
+		{"side not to move in check", "4k3/8/8/8/8/8/8/4R1K1 w - - 0 1", "off-move king in check"},
+		{"castling without king at home", "4k3/8/8/8/8/8/8/R2K3R w KQ - 0 1", "without king and rook"},
+		{"castling without rook at home", "r3k3/8/8/8/8/8/8/4K3 b k - 0 1", "without king and rook"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(0, 0)
+			playMoves(t, g, "e2e4", "e7e5", "g1f3")
+			fen, moves, history := g.FEN(), g.moveCount, len(g.history)
+			err := g.LoadFEN(tt.fen)
+			if err == nil {
+				t.Fatalf("LoadFEN(%q) accepted", tt.fen)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadFEN(%q) = %v, want %q", tt.fen, err, tt.want)
+			}
+			if g.FEN() != fen || g.moveCount != moves || len(g.history) != history {
+				t.Errorf("rejected load changed the game: %s, move %d, %d in history", g.FEN(), g.moveCount, len(g.history))
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+)
+
+// uiFaces are the typefaces a profile can pick for the game's text. The
+// layout is built on 7-pixel characters and 13-pixel lines on the 160x200
+// canvas, so every face is sized to match; the canvas, text and all, is
+// scaled up whole to fill the window.
+var uiFaces = []struct {
+	name string
+	face font.Face
+}{
+	{"PIXEL", basicfont.Face7x13},
+	{"GO MONO", openTypeFace(gomono.TTF, 11)},
+}
+
+// openTypeFace loads an embedded TrueType or OpenType font at size pixels.
+func openTypeFace(data []byte, size float64) font.Face {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		panic("font: " + err.Error())
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		panic("font: " + err.Error())
+	}
+	return face
+}
+
+// uiFace is the typeface the current profile reads in. A profile saved with
+// a face this build doesn't have reads in the first.
+func uiFace() font.Face {
+	if profile == nil || profile.Settings.Font < 0 || profile.Settings.Font >= len(uiFaces) {
+		return uiFaces[0].face
+	}
+	return uiFaces[profile.Settings.Font].face
+}
+
+// drawText draws s with its baseline starting at x, y in the profile's face.
+func drawText(dst *ebiten.Image, s string, x, y int, clr color.Color) {
+	text.Draw(dst, s, uiFace(), x, y, clr)
+}
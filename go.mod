@@ -3,6 +3,7 @@ module github.com/ngolebiewski/chess
 go 1.25.1
 
 require (
+	github.com/ebitengine/oto/v3 v3.4.0
 	github.com/hajimehoshi/ebiten/v2 v2.9.7
 	golang.org/x/image v0.31.0
 )
@@ -14,4 +15,5 @@ require (
 	github.com/jezek/xgb v1.1.1 // indirect
 	golang.org/x/sync v0.17.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
 )
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonCommand is one line of input to jsonLoop. Exactly one field is set:
+//
+//	{"move":"e2e4"}            play a move, in long algebraic or SAN
+//	{"newgame":{"fen":"..."}}  start over, from the standard position if
+//	                           fen is empty
+//	{"getstate":true}          just report the state
+type jsonCommand struct {
+	Move    string `json:"move"`
+	NewGame *struct {
+		FEN string `json:"fen"`
+	} `json:"newgame"`
+	GetState bool `json:"getstate"`
+}
+
+// jsonState is jsonLoop's reply to every command. Status is "playing" or, once
+// the game is over, how it ended, named as on the -selfplay result line.
+type jsonState struct {
+	FEN        string   `json:"fen"`
+	Turn       string   `json:"turn"` // "white" or "black"
+	LegalMoves []string `json:"legalMoves"`
+	LastMove   string   `json:"lastMove,omitempty"`
+	Status     string   `json:"status"`
+	Result     string   `json:"result"` // as the PGN Result tag
+	Error      string   `json:"error,omitempty"`
+}
+
+// jsonLoop reads JSON commands from in, one per line, and answers each with
+// the game's state as one line of JSON on out. A command that can't be read
+// or carried out leaves the game as it was and sets the reply's error. Both
+// sides' moves come from the caller; Frank doesn't play. It returns the
+// process exit code.
+func jsonLoop(in io.Reader, out io.Writer) int {
+	g := newUCIGame()
+	enc := json.NewEncoder(out)
+	sc := bufio.NewScanner(in)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var cmd jsonCommand
+		err := json.Unmarshal([]byte(line), &cmd)
+		if err == nil {
+			g, err = g.runJSON(cmd)
+		}
+		st := g.jsonState()
+		if err != nil {
+			st.Error = err.Error()
+		}
+		if err := enc.Encode(st); err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// runJSON carries out cmd, returning the game to carry on with.
+func (g *Game) runJSON(cmd jsonCommand) (*Game, error) {
+	switch {
+	case cmd.NewGame != nil:
+		ng := newUCIGame()
+		if cmd.NewGame.FEN != "" {
+			if err := ng.LoadFEN(cmd.NewGame.FEN); err != nil {
+				return g, err
+			}
+		}
+		return ng, nil
+	case cmd.Move != "":
+		if g.gameOver {
+			return g, errors.New("game over")
+		}
+		m, err := g.parseMove(cmd.Move)
+		if err != nil {
+			return g, fmt.Errorf("%s: %s", cmd.Move, strings.ToLower(err.Error()))
+		}
+		g.executeMove(m)
+		if _, winner, over := g.outcome(); over {
+			g.gameOver, g.winner = true, winner
+		}
+		return g, nil
+	case cmd.GetState:
+		return g, nil
+	}
+	return g, errors.New("unknown command")
+}
+
+// jsonState describes the game for a reply.
+func (g *Game) jsonState() jsonState {
+	st := jsonState{FEN: g.FEN(), Turn: "white", LegalMoves: []string{}, Status: "playing", Result: g.resultTag()}
+	if g.activeColor == Black {
+		st.Turn = "black"
+	}
+	if len(g.history) > 0 {
+		st.LastMove = g.lastUCI()
+	}
+	if reason, _, over := g.outcome(); over {
+		st.Status = selfPlayReasons[reason]
+		return st
+	}
+	for _, m := range g.LegalMoves(g.activeColor) {
+		s := toAlg(m.From.X, m.From.Y) + toAlg(m.To.X, m.To.Y)
+		if p := g.board[m.From.Y][m.From.X]; p.Type == Pawn && m.To.Y == g.lastRank(p.Color) {
+			for _, promo := range "qrbn" {
+				st.LegalMoves = append(st.LegalMoves, s+string(promo))
+			}
+			continue
+		}
+		st.LegalMoves = append(st.LegalMoves, s)
+	}
+	return st
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// jsonSession runs commands through jsonLoop and returns its replies, one per
+// command.
+func jsonSession(t *testing.T, commands ...string) []jsonState {
+	t.Helper()
+	var out strings.Builder
+	if code := jsonLoop(strings.NewReader(strings.Join(commands, "\n")), &out); code != 0 {
+		t.Fatalf("jsonLoop exited %d", code)
+	}
+	var replies []jsonState
+	dec := json.NewDecoder(strings.NewReader(out.String()))
+	for dec.More() {
+		var st jsonState
+		if err := dec.Decode(&st); err != nil {
+			t.Fatal(err)
+		}
+		replies = append(replies, st)
+	}
+	if len(replies) != len(commands) {
+		t.Fatalf("%d replies to %d commands", len(replies), len(commands))
+	}
+	return replies
+}
+
+func TestJSONRejectsIllegalMoves(t *testing.T) {
+	replies := jsonSession(t,
+		`{"getstate":true}`,
+		`{"move":"e2e5"}`, // no pawn moves three squares
+		`{"move":"e7e5"}`, // Black's pawn, on White's turn
+		`{"move":"Ke2"}`,  // the king's way is blocked
+	)
+	start := replies[0].FEN
+	for i, st := range replies[1:] {
+		if st.Error == "" {
+			t.Errorf("reply %d: no error", i+1)
+		}
+		if st.FEN != start {
+			t.Errorf("reply %d: FEN %s, want it unchanged as %s", i+1, st.FEN, start)
+		}
+	}
+}
+
+func TestJSONSession(t *testing.T) {
+	replies := jsonSession(t,
+		`{"newgame":{}}`,
+		`{"move":"e2e4"}`,
+		`{"move":"Nf6"}`,
+		`{"getstate":true}`,
+		`{"newgame":{"fen":"6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1"}}`,
+		`{"move":"Ra8#"}`,
+		`{"move":"g8h8"}`,
+		`{"getstate":true}`,
+	)
+	tests := []struct {
+		fen, turn, last, status, result string
+		moves                           int
+	}{
+		{standardFEN, "white", "", "playing", "*", 20},
+		{"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", "black", "e2e4", "playing", "*", 20},
+		{"rnbqkb1r/pppppppp/5n2/8/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 1 2", "white", "g8f6", "playing", "*", 30},
+		{"rnbqkb1r/pppppppp/5n2/8/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 1 2", "white", "g8f6", "playing", "*", 30},
+		{"6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1", "white", "", "playing", "*", 20},
+		{"R5k1/5ppp/8/8/8/8/5PPP/6K1 b - - 1 1", "black", "a1a8", "checkmate", "1-0", 0},
+		{"R5k1/5ppp/8/8/8/8/5PPP/6K1 b - - 1 1", "black", "a1a8", "checkmate", "1-0", 0},
+		{"R5k1/5ppp/8/8/8/8/5PPP/6K1 b - - 1 1", "black", "a1a8", "checkmate", "1-0", 0},
+	}
+	for i, tt := range tests {
+		st := replies[i]
+		if st.FEN != tt.fen || st.Turn != tt.turn || st.LastMove != tt.last || st.Status != tt.status || st.Result != tt.result || len(st.LegalMoves) != tt.moves {
+			t.Errorf("reply %d: %+v\nwant %+v", i, st, tt)
+		}
+		if wantErr := i == 6; (st.Error != "") != wantErr {
+			t.Errorf("reply %d: error %q", i, st.Error)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+// The mating lessons teach a beginner to mate a bare king. In the ladder mate
+// a queen and a rook take turns cutting the king off a rank at a time until
+// he is pinned to the edge; in the queen mate the queen shrinks his box on
+// her own and White's king walks up to help finish him. Frank defends by
+// running for the middle. After each of his moves the lesson works out which
+// of the player's moves squeeze him hardest, for the board to show, and which
+// would stalemate him and throw the win away.
+
+// The lessons' starting positions: the black king in the middle, White's
+// king and heavy pieces at home.
+const (
+	ladderFEN    = "8/8/8/4k3/8/8/8/R3K2Q w - - 0 1"
+	queenMateFEN = "8/8/8/4k3/8/8/8/3QK3 w - - 0 1"
+)
+
+// lessonSetup is one of the mating lessons, named as the HUD shows it.
+type lessonSetup struct {
+	name, fen string
+}
+
+// lessons run in this order, a mate moving on to the next one: the ladder
+// mate first, as the easier, then the queen mate.
+var lessons = []lessonSetup{
+	{"LADDER MATE", ladderFEN},
+	{"QUEEN MATE", queenMateFEN},
+}
+
+// NewLessonGame sets up lesson i. Like puzzles, it has no clock and no
+// stakes.
+func NewLessonGame(i int) *Game {
+	g := NewGame(0, 0)
+	if err := g.LoadFEN(lessons[i].fen); err != nil {
+		panic("lesson: " + err.Error())
+	}
+	g.lesson, g.lessonIdx = true, i
+	g.currentDialog = "Box him in. ESC:menu"
+	g.coachLesson()
+	return g
+}
+
+// coachLesson sorts White's moves in the lesson position: the good ones mate,
+// force a mate next move, or else score best by lessonScore without hanging
+// a piece, and the stalemating ones must be avoided.
+func (g *Game) coachLesson() {
+	g.lessonGood, g.lessonStalemates = nil, nil
+	best := -1
+	for _, m := range g.LegalMoves(White) {
+		c := g.clone()
+		c.executeMove(m)
+		score := 0 // mate leaves him nowhere
+		switch {
+		case c.hasLegalMoves(Black) && c.leavesLoose(White):
+			continue
+		case c.hasLegalMoves(Black) && c.forcesMate():
+			score = 1 // mate next move, whatever he does
+		case c.hasLegalMoves(Black):
+			// A position seen before counts as a square more room each
+			// time, so going back and forth loses out to getting somewhere.
+			score = 2 + c.lessonScore() + 16*g.repetitions[c.positionKey()]
+		case !c.isInCheck(Black):
+			g.lessonStalemates = append(g.lessonStalemates, m)
+			continue
+		}
+		if best < 0 || score < best {
+			best, g.lessonGood = score, nil
+		}
+		if score == best {
+			g.lessonGood = append(g.lessonGood, m)
+		}
+	}
+}
+
+// lessonScore ranks a position after one of White's moves, lower being
+// better: first the black king's room, then how far away White's king is,
+// then a check, which only chases him about without mating him.
+func (g *Game) lessonScore() int {
+	score := (g.kingRoom(Black)*8 + g.kingsApart()) * 2
+	if g.isInCheck(Black) {
+		score++
+	}
+	return score
+}
+
+// forcesMate reports whether, with Black to move, every reply he has walks
+// into a mate.
+func (g *Game) forcesMate() bool {
+	for _, r := range g.LegalMoves(Black) {
+		c := g.clone()
+		c.executeMove(r)
+		if !c.canMate() {
+			return false
+		}
+	}
+	return true
+}
+
+// canMate reports whether White has a mate in one.
+func (g *Game) canMate() bool {
+	for _, m := range g.LegalMoves(White) {
+		c := g.clone()
+		c.executeMove(m)
+		if !c.hasLegalMoves(Black) && c.isInCheck(Black) {
+			return true
+		}
+	}
+	return false
+}
+
+// kingsApart is how many king steps separate the two kings.
+func (g *Game) kingsApart() int {
+	var kings [2]Pos
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if p := g.board[y][x]; p != nil && p.Type == King {
+				kings[p.Color] = Pos{x, y}
+			}
+		}
+	}
+	return max(abs(kings[0].X-kings[1].X), abs(kings[0].Y-kings[1].Y))
+}
+
+// leavesLoose reports whether the other side can take one of c's pieces for
+// nothing: a legal capture of a piece none of c's others guard.
+func (g *Game) leavesLoose(c Color) bool {
+	for _, m := range g.LegalMoves(1 - c) {
+		if g.board[m.To.Y][m.To.X] != nil && len(g.attackersOf(m.To.X, m.To.Y, c)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// kingRoom counts the squares c's king could walk to, one step at a time,
+// without ever crossing a square the other side attacks.
+func (g *Game) kingRoom(c Color) int {
+	b := g.clone()
+	var stack []Pos
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if p := b.board[y][x]; p != nil && p.Type == King && p.Color == c {
+				stack = append(stack, Pos{x, y})
+				b.board[y][x] = nil // so he doesn't shade the squares behind him
+			}
+		}
+	}
+	var seen [8][8]bool
+	room := 0
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				x, y := s.X+dx, s.Y+dy
+				if x < 0 || x > 7 || y < 0 || y > 7 || seen[y][x] {
+					continue
+				}
+				seen[y][x] = true
+				if p := b.board[y][x]; p != nil && p.Color == c || len(b.attackersOf(x, y, 1-c)) > 0 {
+					continue
+				}
+				room++
+				stack = append(stack, Pos{x, y})
+			}
+		}
+	}
+	return room
+}
+
+// lessonDefence is Frank's move as the lone king: whichever leaves him the
+// most room, taking a loose piece when he can.
+func (g *Game) lessonDefence() (Move, bool) {
+	moves := g.LegalMoves(Black)
+	if len(moves) == 0 {
+		return Move{}, false
+	}
+	g.rng.Shuffle(len(moves), func(i, j int) { moves[i], moves[j] = moves[j], moves[i] })
+	best, most := moves[0], -1
+	for _, m := range moves {
+		if g.board[m.To.Y][m.To.X] != nil {
+			return m, true
+		}
+		c := g.clone()
+		c.executeMove(m)
+		if room := c.kingRoom(Black); room > most {
+			best, most = m, room
+		}
+	}
+	return best, true
+}
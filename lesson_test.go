@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Playing any of the moves the lesson marks best, against Frank's defence,
+// mates in time and never stalemates.
+func TestLessonsMate(t *testing.T) {
+	limits := []int{25, 50} // plies, by lesson
+	for i, l := range lessons {
+		for seed := int64(0); seed < 10; seed++ {
+			g := NewLessonGame(i)
+			g.demo = true
+			g.rng = rand.New(rand.NewSource(seed))
+			for g.hasLegalMoves(g.activeColor) && len(g.history) <= limits[i] {
+				if g.activeColor == White {
+					g.executeMove(g.lessonGood[g.rng.Intn(len(g.lessonGood))])
+					continue
+				}
+				m, _ := g.lessonDefence()
+				g.executeMove(m)
+				g.coachLesson()
+			}
+			switch {
+			case g.hasLegalMoves(g.activeColor):
+				t.Errorf("%s, seed %d: no mate in %d plies: %s", l.name, seed, limits[i], g.FEN())
+			case !g.isInCheck(g.activeColor):
+				t.Errorf("%s, seed %d: stalemate: %s", l.name, seed, g.FEN())
+			}
+		}
+	}
+}
+
+func TestLessonMarksStalemate(t *testing.T) {
+	g := NewGame(0, 0)
+	if err := g.LoadFEN("7k/8/5K2/8/8/8/8/6Q1 w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	g.lesson = true
+	g.coachLesson()
+	for _, tt := range []struct {
+		move      string
+		stalemate bool
+	}{{"Qg6", true}, {"Qg7", false}} {
+		m, err := g.parseMove(tt.move)
+		if err != nil {
+			t.Fatal(err)
+		}
+		same := func(o Move) bool { return o.From == m.From && o.To == m.To }
+		if got := slices.ContainsFunc(g.lessonStalemates, same); got != tt.stalemate {
+			t.Errorf("%s marked stalemating %v, want %v", tt.move, got, tt.stalemate)
+		}
+		if good := slices.ContainsFunc(g.lessonGood, same); good == tt.stalemate {
+			t.Errorf("%s marked best %v, want %v", tt.move, good, !tt.stalemate)
+		}
+	}
+}
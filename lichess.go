@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// loadLichessPuzzles reads a file in the Lichess puzzle database's CSV
+// format,
+//
+//	PuzzleId,FEN,Moves,Rating,RatingDeviation,Popularity,NbPlays,Themes,...
+//
+// keeping the puzzles rated minRating to maxRating and, unless theme is
+// empty, tagged with theme. A Lichess FEN is the position before the
+// opponent's move, the first of Moves; the rest are the solution. The
+// returned puzzles start after that first move, and as the puzzle mode
+// seats the player as White, a puzzle with Black to play is flipped: ranks
+// mirrored and colors swapped, which plays exactly the same.
+func loadLichessPuzzles(path string, minRating, maxRating int, theme string) ([]Puzzle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1 // OpeningTags is missing from older exports
+	var ps []Puzzle
+	for line := 1; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && rec[0] == "PuzzleId" {
+			continue
+		}
+		if len(rec) < 8 {
+			return nil, fmt.Errorf("%s:%d: %d fields, want at least 8", path, line, len(rec))
+		}
+		rating, err := strconv.Atoi(rec[3])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: rating %q", path, line, rec[3])
+		}
+		themes := strings.Fields(rec[7])
+		if rating < minRating || rating > maxRating || theme != "" && !slices.Contains(themes, theme) {
+			continue
+		}
+		p, err := lichessPuzzle(rec[0], rec[1], strings.Fields(rec[2]))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		p.Rating, p.Themes = rating, themes
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+// lichessPuzzle turns one Lichess puzzle into a Puzzle, playing the
+// opponent's opening move and checking the solution replays legally.
+func lichessPuzzle(id, fen string, moves []string) (Puzzle, error) {
+	if len(moves) < 2 {
+		return Puzzle{}, errors.New("no solution")
+	}
+	g := NewGame(0, 0)
+	g.demo = true
+	if err := g.LoadFEN(fen); err != nil {
+		return Puzzle{}, err
+	}
+	start := ""
+	for i, uci := range moves {
+		m, err := g.parseMove(uci)
+		if err != nil {
+			return Puzzle{}, fmt.Errorf("%s: %s", uci, strings.ToLower(err.Error()))
+		}
+		g.executeMove(m)
+		if i == 0 {
+			start = g.FEN()
+		}
+	}
+	p := Puzzle{Name: "LICHESS " + id, FEN: start, Solution: moves[1:]}
+	if strings.Fields(start)[1] == "b" {
+		p.FEN = mirrorFEN(start)
+		p.Solution = nil
+		for _, uci := range moves[1:] {
+			p.Solution = append(p.Solution, mirrorUCI(uci))
+		}
+	}
+	return p, nil
+}
+
+// mirrorFEN flips a position top to bottom and swaps the colors, so the
+// other side is to move in the same position.
+func mirrorFEN(fen string) string {
+	f := strings.Fields(fen)
+	ranks := strings.Split(f[0], "/")
+	slices.Reverse(ranks)
+	f[0] = swapCase(strings.Join(ranks, "/"))
+	f[1] = map[string]string{"w": "b", "b": "w"}[f[1]]
+	if len(f) > 2 && f[2] != "-" {
+		rights := ""
+		for _, r := range "KQkq" {
+			if strings.ContainsAny(f[2], swapCase(string(r))) {
+				rights += string(r)
+			}
+		}
+		f[2] = rights
+	}
+	if len(f) > 3 && f[3] != "-" {
+		f[3] = mirrorUCI(f[3])
+	}
+	return strings.Join(f, " ")
+}
+
+// mirrorUCI flips the ranks of a long-algebraic move, or a square, to go
+// with mirrorFEN.
+func mirrorUCI(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= '1' && c <= '8' {
+			b[i] = '1' + '8' - c
+		}
+	}
+	return string(b)
+}
+
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r - 'A' + 'a'
+		}
+		return r
+	}, s)
+}
+
+// parseRatingRange reads a -rating flag such as "1200-1600". Either end can
+// be left off, but the low end can't be above the high one.
+func parseRatingRange(s string) (lo, hi int, err error) {
+	lo, hi = 0, 1<<31-1
+	if s == "" {
+		return lo, hi, nil
+	}
+	a, b, ok := strings.Cut(s, "-")
+	if !ok {
+		b = a
+	}
+	if a != "" {
+		if lo, err = strconv.Atoi(a); err != nil {
+			return 0, 0, fmt.Errorf("rating %q: want LOW-HIGH", s)
+		}
+	}
+	if b != "" {
+		if hi, err = strconv.Atoi(b); err != nil {
+			return 0, 0, fmt.Errorf("rating %q: want LOW-HIGH", s)
+		}
+	}
+	if lo > hi {
+		return 0, 0, fmt.Errorf("rating %q: low end above high end", s)
+	}
+	return lo, hi, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+const lichessFixture = "testdata/lichess_puzzles.csv"
+
+func TestLoadLichessPuzzles(t *testing.T) {
+	tests := []struct {
+		name    string
+		lo, hi  int
+		theme   string
+		wantIDs []string
+	}{
+		{"everything", 0, 1<<31 - 1, "", []string{"W0001", "B0001", "E0001"}},
+		{"rating range", 900, 1300, "", []string{"W0001", "B0001"}},
+		{"single rating", 1300, 1300, "", []string{"B0001"}},
+		{"theme", 0, 1<<31 - 1, "endgame", []string{"E0001"}},
+		{"rating and theme", 1200, 2000, "mateIn1", []string{"B0001"}},
+		{"nothing matches", 2000, 3000, "", nil},
+	}
+	for _, tt := range tests {
+		ps, err := loadLichessPuzzles(lichessFixture, tt.lo, tt.hi, tt.theme)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var ids []string
+		for _, p := range ps {
+			ids = append(ids, p.Name[len("LICHESS "):])
+		}
+		if !slices.Equal(ids, tt.wantIDs) {
+			t.Errorf("%s: got %v, want %v", tt.name, ids, tt.wantIDs)
+		}
+	}
+}
+
+// Each puzzle starts after the opponent's first move with White to play,
+// a Black-to-play one mirrored, and its solution mates from there.
+func TestLichessPuzzleStart(t *testing.T) {
+	ps, err := loadLichessPuzzles(lichessFixture, 0, 1<<31-1, "mateIn1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		// after 3...d6, as it stands
+		"LICHESS W0001": "r1bqkbnr/ppp2ppp/2np4/4p3/2B1P3/5Q2/PPPP1PPP/RNB1K1NR w KQkq - 0 4",
+		// after 4.d3, flipped so White plays ...Qxf2# as Qxf7#
+		"LICHESS B0001": "r1bqk1nr/ppp2ppp/2np4/2b1p3/2B1P3/5Q2/PPPP1PPP/RNB1K1NR w KQkq - 0 4",
+	}
+	for _, p := range ps {
+		if p.FEN != want[p.Name] {
+			t.Errorf("%s: FEN %s, want %s", p.Name, p.FEN, want[p.Name])
+		}
+		if !slices.Equal(p.Solution, []string{"f3f7"}) {
+			t.Errorf("%s: solution %v, want [f3f7]", p.Name, p.Solution)
+		}
+		g := NewGame(0, 0)
+		if err := g.LoadFEN(p.FEN); err != nil {
+			t.Fatalf("%s: %v", p.Name, err)
+		}
+		playMoves(t, g, p.Solution...)
+		if g.hasLegalMoves(Black) || !g.isInCheck(Black) {
+			t.Errorf("%s: solution doesn't mate: %s", p.Name, g.FEN())
+		}
+	}
+}
+
+func TestParseRatingRange(t *testing.T) {
+	const top = 1<<31 - 1
+	tests := []struct {
+		in     string
+		lo, hi int
+		ok     bool
+	}{
+		{"", 0, top, true},
+		{"1200-1600", 1200, 1600, true},
+		{"1200-", 1200, top, true},
+		{"-1600", 0, 1600, true},
+		{"1500", 1500, 1500, true},
+		{"abc", 0, 0, false},
+		{"1200-high", 0, 0, false},
+		{"1200-1400-1600", 0, 0, false},
+		{"1600-1200", 0, 0, false},
+	}
+	for _, tt := range tests {
+		lo, hi, err := parseRatingRange(tt.in)
+		if (err == nil) != tt.ok || lo != tt.lo || hi != tt.hi {
+			t.Errorf("parseRatingRange(%q) = %d, %d, %v; want %d, %d, ok %v", tt.in, lo, hi, err, tt.lo, tt.hi, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+// Los Alamos chess is played on a 6x6 board with no bishops: each side has a
+// rook, knight, queen, king, knight and rook on its back rank and six pawns
+// in front. Pawns step one square at a time, so there is no en passant,
+// nobody castles, and pawns promote on the far rank, but never to a bishop.
+//
+// Rather than resizing the board, a Los Alamos game plays on the middle six
+// files and ranks of the usual [8][8] board, with onBoard fencing off the
+// ring around them. Move generation, search and evaluation go on scanning
+// all 64 squares and simply never find a legal move into the ring. What
+// still assumes 8x8, and would have to change for a true 6x6 board:
+//
+//   - the board itself, [8][8]*ChessPiece, and every 0..7 loop over it in
+//     move generation, evaluation, dead-position checks, the editor and FEN;
+//   - notation: toAlg names squares by their place on the 8x8 board, so a
+//     Los Alamos game's SAN, PGN and FEN read b2 to g7 rather than a1 to f6,
+//     and FEN always has eight ranks of eight;
+//   - LoadFEN, the board editor and -fen, which only make standard games,
+//     so a Los Alamos position can't be loaded back as one;
+//   - the first-move pawn double step, keyed to ranks 2 and 7, which Los
+//     Alamos pawns never stand on, and castling, keyed to the a- and h-file
+//     rooks, whose rights a Los Alamos game starts without;
+//   - the drawing, renderSquare and boardSquare, which lay out ten tiles
+//     including the border; the fenced-off ring is drawn as border;
+//   - Frank's scripted scholar's-mate trap, which names 8x8 squares and so
+//     never fits a Los Alamos board.
+
+// NewLosAlamosGame is NewGame set up for Los Alamos chess.
+func NewLosAlamosGame(wager int, minutes int) *Game {
+	g := NewGame(wager, minutes)
+	g.losAlamos = true
+	g.rebuildBoard()
+	return g
+}
+
+// losAlamosLayout is both back ranks, from the left of the 6x6 board.
+var losAlamosLayout = []PieceType{Rook, Knight, Queen, King, Knight, Rook}
+
+// setupLosAlamos lays the pieces out in the middle 6x6 of the board.
+func (g *Game) setupLosAlamos() {
+	g.rookFiles = [2]int{1, 6}
+	g.castling = [2][2]bool{}
+	for i, t := range losAlamosLayout {
+		g.createPiece(t, Black, i+1, 1)
+		g.createPiece(Pawn, Black, i+1, 2)
+		g.createPiece(Pawn, White, i+1, 5)
+		g.createPiece(t, White, i+1, 6)
+	}
+}
+
+// onBoard reports whether x, y is a square of the game's board: any of the
+// 64, or only the middle 36 in Los Alamos chess.
+func (g *Game) onBoard(x, y int) bool {
+	lo, hi := 0, 7
+	if g.losAlamos {
+		lo, hi = 1, 6
+	}
+	return x >= lo && x <= hi && y >= lo && y <= hi
+}
+
+// lastRank is the row c's pawns promote on, which is also the other side's
+// back rank.
+func (g *Game) lastRank(c Color) int {
+	r := 0
+	if g.losAlamos {
+		r = 1
+	}
+	if c == Black {
+		return 7 - r
+	}
+	return r
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLosAlamosStaysInside(t *testing.T) {
+	g := NewLosAlamosGame(0, 5)
+	g.demo = true
+	rng := rand.New(rand.NewSource(1))
+	for ply := 0; ply < 60; ply++ {
+		if _, _, over := g.outcome(); over {
+			break
+		}
+		moves := g.LegalMoves(g.activeColor)
+		for _, m := range moves {
+			if !g.onBoard(m.To.X, m.To.Y) {
+				t.Fatalf("ply %d: legal move %s%s into the ring in %s", ply, toAlg(m.From.X, m.From.Y), toAlg(m.To.X, m.To.Y), g.FEN())
+			}
+		}
+		g.executeMove(moves[rng.Intn(len(moves))])
+	}
+}
+
+func TestLosAlamosPromotion(t *testing.T) {
+	g := NewLosAlamosGame(0, 5)
+	g.hotseat = true // so Black picks too
+	g.clearEdit()
+	g.createPiece(King, White, 1, 6)
+	g.createPiece(King, Black, 6, 3)
+	g.createPiece(Pawn, White, 3, 2) // d6, promoting on d7
+	g.createPiece(Pawn, Black, 5, 5) // f3, promoting on f2
+	if g.lastRank(White) != 1 || g.lastRank(Black) != 6 {
+		t.Fatalf("lastRank = %d, %d, want 1, 6", g.lastRank(White), g.lastRank(Black))
+	}
+	for _, s := range []string{"d6d7b", "d7=B"} {
+		if _, err := g.parseMove(s); err == nil {
+			t.Errorf("parseMove(%q) allowed a bishop", s)
+		}
+	}
+
+	for _, tt := range []struct {
+		move string
+		sq   Pos
+	}{{"d6d7", Pos{3, 1}}, {"f3f2", Pos{5, 6}}} {
+		m, err := g.parseMove(tt.move)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.move, err)
+		}
+		m.Promo = Pawn // as a click leaves it, with no piece named
+		g.executeMove(m)
+		if !g.promoting {
+			t.Fatalf("%s: no piece picker", tt.move)
+		}
+		g.promote(Bishop)
+		if p := g.board[tt.sq.Y][tt.sq.X]; !g.promoting || p.Type != Pawn {
+			t.Errorf("%s: promoted to a bishop", tt.move)
+		}
+		g.promote(Knight)
+		if p := g.board[tt.sq.Y][tt.sq.X]; g.promoting || p.Type != Knight {
+			t.Errorf("%s: promote(Knight) left %v, promoting %v", tt.move, p.Type, g.promoting)
+		}
+	}
+}
+
+func TestLoadFENLeavesLosAlamos(t *testing.T) {
+	g := NewLosAlamosGame(0, 5)
+	if err := g.LoadFEN(standardFEN); err != nil {
+		t.Fatal(err)
+	}
+	if g.losAlamos || !g.onBoard(0, 0) || !g.onBoard(7, 7) || g.lastRank(White) != 0 {
+		t.Errorf("loaded game still fenced: losAlamos %v, lastRank %d", g.losAlamos, g.lastRank(White))
+	}
+	if n := len(g.LegalMoves(White)); n != 20 {
+		t.Errorf("%d legal moves from the start, want 20", n)
+	}
+	if got := g.FEN(); got != standardFEN {
+		t.Errorf("FEN = %s, want %s", got, standardFEN)
+	}
+}
@@ -1,20 +1,22 @@
 package main
 
 import (
-	"bytes"
 	_ "embed"
+	"errors"
+	"flag"
 	"fmt"
-	"image"
 	"image/color"
 	_ "image/png"
-	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/hajimehoshi/ebiten/v2/text"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-	"golang.org/x/image/font/basicfont"
 )
 
 //go:embed chess.png
@@ -23,447 +25,1522 @@ var chessData []byte
 const (
 	tileSize = 16
 	gridSize = 10
+
+	// Logical resolution everything is drawn at before being scaled up.
+	screenW = 160
+	screenH = 200
 )
 
-type Color int
+var sprites []*ebiten.Image
+var profile *Profile
+var touchIDs []ebiten.TouchID
+
+// The logical canvas is blown up to the window by a whole-number factor so
+// every art pixel stays a crisp square; view records that scale and the
+// letterbox offset so pointer input can be mapped back.
+var canvas *ebiten.Image
+var view = struct{ scale, x, y int }{scale: 1}
+
+// toLogical maps a window position to canvas coordinates.
+func toLogical(x, y int) (int, int) {
+	return (x - view.x) / view.scale, (y - view.y) / view.scale
+}
+
+var variantIdx int    // index into variants picked on the menu
+var oddsIdx int       // index into materialOdds picked on the menu
+var instantFrank bool // skip Frank's think-time delay, for testing
+var thinkPace = 100   // percent of his usual think time Frank takes
+var opponentIdx int   // index into opponents picked on the menu
+var berserk bool      // the player halves their clock for a bigger payout
+
+// variants are the kinds of game the menu's F key steps through, with their
+// labels there.
+var variants = []struct {
+	name    string
+	newGame func(wager, minutes int) *Game
+}{
+	{"CLASSIC", NewGame},
+	{"960", NewChess960Game},
+	{"6x6", NewLosAlamosGame},
+	{"ARMAGED", NewArmageddonGame},
+}
 
+// startGame begins a new match in whichever variant and against whichever
+// opponent the menu is set to.
+func startGame(wager, minutes int) *Game {
+	g := variants[variantIdx].newGame(wager, minutes)
+	g.seat(&opponents[opponentIdx])
+	g.currentDialog = g.rival().Greeting
+	g.payoutRate = payoutRates[profile.Settings.PayoutRate].percent
+	if oddsIdx > 0 {
+		g.odds = oddsIdx
+		g.rebuildBoard()
+		if wager > 0 {
+			g.currentDialog = fmt.Sprintf(materialOdds[g.odds].line, g.payout())
+		}
+	}
+	tc := timeControls[profile.Settings.TimeControl]
+	g.increment, g.bronstein = float64(tc.secs*ticksPerSecond), tc.bronstein
+	g.blackTime /= float64(timeOdds[profile.Settings.TimeOdds].div)
+	g.budget = searchBudgets[profile.Settings.SearchBudget].searchBudget
+	if wager > 0 {
+		g.countdown = 4 * countdownStep
+	}
+	g.rated = rated
+	if berserk {
+		g.berserk = true
+		g.whiteTime /= 2
+		if wager > 0 {
+			g.currentDialog = fmt.Sprintf("Half a clock? Wins pay $%d.", g.payout())
+		}
+	}
+	g.startTimes = [2]float64{Black: g.blackTime, White: g.whiteTime}
+	if profile.Bailed {
+		g.currentDialog = "Ran off last time, huh?"
+		profile.Bailed = false
+		profile.save()
+	}
+	return g
+}
+
+// newPositionGame is the unstaked 5-minute game a position is loaded into,
+// from -fen, a dropped file or a branch off the review screen. It seats the
+// hustler picked on the menu and follows the clock and search settings, but
+// not the menu's variant, odds or berserk: the position brings its own
+// board.
+func newPositionGame() *Game {
+	g := NewGame(0, 5)
+	g.seat(&opponents[opponentIdx])
+	g.currentDialog = g.rival().Greeting
+	tc := timeControls[profile.Settings.TimeControl]
+	g.increment, g.bronstein = float64(tc.secs*ticksPerSecond), tc.bronstein
+	g.budget = searchBudgets[profile.Settings.SearchBudget].searchBudget
+	return g
+}
+
+// newHotseatGame starts an unstaked game for two players taking turns at one
+// board, on the time control from the settings. Nobody sits across the board,
+// so Frank never moves; the second player goes down in the PGN as a guest.
+func newHotseatGame() *Game {
+	g := variants[variantIdx].newGame(0, 5)
+	g.hotseat, g.hustlerName, g.currentDialog = true, "Guest", ""
+	tc := timeControls[profile.Settings.TimeControl]
+	g.increment, g.bronstein = float64(tc.secs*ticksPerSecond), tc.bronstein
+	return g
+}
+
+// newDemoGame is the self-play game shown behind the stakes menu.
+func newDemoGame() *Game {
+	g := NewGame(0, 0)
+	g.demo = true
+	return g
+}
+
+// stepDemo advances the attract-mode game, starting over once it's done.
+func (g *Game) stepDemo() {
+	if g.demoGame == nil {
+		g.demoGame = newDemoGame()
+	}
+	d := g.demoGame
+	d.tickAnimations()
+	d.frankThinkTime++
+	if d.frankThinkTime < 40 {
+		return
+	}
+	if _, _, over := d.outcome(); over || d.moveCount >= 200 {
+		g.demoGame = newDemoGame()
+		return
+	}
+	if m, ok := d.frankMove(d.activeColor); ok {
+		d.executeMove(m)
+	}
+}
+
+// tickAnimations ages the short-lived board effects by one frame.
+func (g *Game) tickAnimations() {
+	if g.epFade.frames > 0 {
+		g.epFade.frames--
+	}
+	if g.slide.frames > 0 {
+		g.slide.frames--
+	}
+	if g.cueFrames > 0 {
+		g.cueFrames--
+	}
+	if g.snap.frames > 0 {
+		g.snap.frames--
+	}
+}
+
+// pointerHoldFrames is how long clicks and taps are ignored after the screen
+// changes, say from the menu to a game or from one game to the next. A click
+// stays "just pressed" for the rest of the frame it lands in, so without the
+// hold the click that starts a game could also land on its board, and a
+// double click meant for one screen would spill into the next.
+const pointerHoldFrames = 10
+
+var pointerHold int // frames left before clicks count again
+
+// pointerJustPressed reports a fresh left click or touch tap and where it
+// landed, in canvas coordinates for both.
+func pointerJustPressed() (int, int, bool) {
+	if pointerHold > 0 {
+		return 0, 0, false
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := toLogical(ebiten.CursorPosition())
+		return x, y, true
+	}
+	touchIDs = inpututil.AppendJustPressedTouchIDs(touchIDs[:0])
+	if len(touchIDs) > 0 {
+		x, y := toLogical(ebiten.TouchPosition(touchIDs[0]))
+		return x, y, true
+	}
+	return 0, 0, false
+}
+
+// pointerHeld reports whether the left button or a finger is down, and
+// where, in canvas coordinates.
+func pointerHeld() (int, int, bool) {
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		x, y := toLogical(ebiten.CursorPosition())
+		return x, y, true
+	}
+	touchIDs = ebiten.AppendTouchIDs(touchIDs[:0])
+	if len(touchIDs) > 0 {
+		x, y := toLogical(ebiten.TouchPosition(touchIDs[0]))
+		return x, y, true
+	}
+	return 0, 0, false
+}
+
+// Stakes menu rows, by text baseline, so taps can pick an option.
+var menuOptions = []struct {
+	label       string
+	wager, mins int
+	baseline    int
+}{
+	{"1: $5 Bullet", 5, 1, 57},
+	{"2: $50 Blitz", 50, 5, 71},
+}
+
+// Baselines of the other stakes-menu rows.
 const (
-	Black Color = iota
-	White
+	stakesHeaderBaseline = 42
+	variantBaseline      = 85
+	puzzleBaseline       = 99
+	lessonBaseline       = 113
+	styleBaseline        = 127
+	settingsBaseline     = 141
+	walletBaseline       = 156
+	profileBaseline      = 170
 )
 
-type PieceType int
+func onMenuRow(x, y, baseline int) bool {
+	return x >= 10 && x < 150 && y >= baseline-11 && y < baseline+4
+}
+
+// settingsRow is one line of the settings screen, backed by the current
+// profile's Settings: value shows it and change steps it on.
+type settingsRow struct {
+	label  string
+	value  func() string
+	change func()
+}
+
+var settingsRows = []settingsRow{
+	toggleRow("Confirm moves", func() *bool { return &profile.Settings.ConfirmMoves }),
+	{"Clock", func() string { return timeControls[profile.Settings.TimeControl].name }, func() {
+		profile.Settings.TimeControl = (profile.Settings.TimeControl + 1) % len(timeControls)
+	}},
+	{"Hustler time", func() string { return timeOdds[profile.Settings.TimeOdds].name }, func() {
+		profile.Settings.TimeOdds = (profile.Settings.TimeOdds + 1) % len(timeOdds)
+	}},
+	toggleRow("Draw counters", func() *bool { return &profile.Settings.DrawCounters }),
+	toggleRow("High contrast", func() *bool { return &profile.Settings.HighContrast }),
+	toggleRow("Hide money", func() *bool { return &profile.Settings.HideMoney }),
+	toggleRow("Visual cues", func() *bool { return &profile.Settings.VisualCues }),
+	toggleRow("Auto-queen", func() *bool { return &profile.Settings.AutoQueen }),
+	{"Search", func() string { return searchBudgets[profile.Settings.SearchBudget].name }, func() {
+		profile.Settings.SearchBudget = (profile.Settings.SearchBudget + 1) % len(searchBudgets)
+	}},
+	toggleRow("Hanging", func() *bool { return &profile.Settings.HangingPieces }),
+	{"Font", func() string { return uiFaces[max(0, profile.Settings.Font)%len(uiFaces)].name }, func() {
+		profile.Settings.Font = (profile.Settings.Font + 1) % len(uiFaces)
+	}},
+	{"Start cash", func() string { return startingCash[profile.Settings.StartingCash].name }, func() {
+		profile.Settings.StartingCash = (profile.Settings.StartingCash + 1) % len(startingCash)
+	}},
+	{"Payouts", func() string { return payoutRates[profile.Settings.PayoutRate].name }, func() {
+		profile.Settings.PayoutRate = (profile.Settings.PayoutRate + 1) % len(payoutRates)
+	}},
+	toggleRow("Easy assist", func() *bool { return &profile.Settings.EasyAssist }),
+	toggleRow("Touch pad", func() *bool { return &profile.Settings.TouchPad }),
+	{"Legal moves", func() string { return moveMarkNames[profile.Settings.MoveMarks] }, func() {
+		profile.Settings.MoveMarks = (profile.Settings.MoveMarks + 1) % len(moveMarkNames)
+	}},
+	{"Pieces", pieceSetName, func() { profile.Settings.PieceSet = "" }},
+}
+
+// The settings screen shows settingsPerPage rows at a time, and Tab turns
+// the page.
+const settingsPerPage = 10
+
+var settingsPage int
+
+// settingsOnPage is the range of settingsRows on the page showing.
+func settingsOnPage() (lo, hi int) {
+	lo = settingsPage * settingsPerPage
+	return lo, min(lo+settingsPerPage, len(settingsRows))
+}
+
+// settingsPages is how many pages the settings screen has.
+func settingsPages() int {
+	return (len(settingsRows) + settingsPerPage - 1) / settingsPerPage
+}
+
+// toggleRow is a settings row that flips a bool between ON and OFF.
+func toggleRow(label string, v func() *bool) settingsRow {
+	return settingsRow{label, func() string {
+		if *v() {
+			return "ON"
+		}
+		return "OFF"
+	}, func() { *v() = !*v() }}
+}
+
+// updateSettings runs the settings screen: a row's number key or a tap
+// changes it, Tab or a tap on "TAB: more" turns the page, and Escape or O
+// goes back to the stakes menu.
+func (g *Game) updateSettings() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		g.showSettings = false
+		return
+	}
+	mx, my, tapped := pointerJustPressed()
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) || tapped && mx >= 90 && onMenuRow(mx, my, settingsBackBaseline) {
+		settingsPage = (settingsPage + 1) % settingsPages()
+		g.menuCursor = 0
+		return
+	}
+	flip := -1
+	lo, hi := settingsOnPage()
+	for i := lo; i < hi; i++ {
+		if inpututil.IsKeyJustPressed(settingsKey(i)) {
+			flip = i
+		}
+	}
+	if flip >= 0 {
+		settingsRows[flip].change()
+		profile.save()
+	}
+	if tapped {
+		items := menuItems(settingsMenu)
+		if i := menuItemAt(items, mx, my); i >= 0 {
+			items[i].choose(g)
+		}
+	}
+}
+
+func settingsRowBaseline(i int) int { return 56 + i%settingsPerPage*13 }
+
+// settingsKey is the number key for settings row i: 1 to 9, then 0 for the
+// tenth row of its page.
+func settingsKey(i int) ebiten.Key {
+	if i%settingsPerPage == 9 {
+		return ebiten.Key0
+	}
+	return ebiten.Key1 + ebiten.Key(i%settingsPerPage)
+}
+
+func (g *Game) updateMenu() {
+	g.stepDemo()
+	if g.tutorial != nil {
+		g.updateTutorial()
+		return
+	}
+	if g.reviewBoard != nil {
+		g.updateReview()
+		return
+	}
+	if g.navigateMenu() {
+		return
+	}
+	if g.showSettings {
+		g.updateSettings()
+		return
+	}
+	if g.enteringName {
+		g.updateNameEntry()
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		if g.confirmReset {
+			profile.reset()
+		}
+		g.confirmReset = !g.confirmReset
+		return
+	}
+	if len(inpututil.AppendJustPressedKeys(nil)) > 0 {
+		g.confirmReset, g.menuNote = false, ""
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) && len(recentGames) > 0 {
+		g.showReview(0, 0)
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		profile = nextProfile(profile.Name)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		g.enteringName, g.nameEntry = true, ""
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		variantIdx = (variantIdx + 1) % len(variants)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyK) {
+		oddsIdx = (oddsIdx + 1) % len(materialOdds)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		berserk = !berserk
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		rated = !rated
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		g.showSettings = true
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		*g = *newHotseatGame()
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		opponentIdx = (opponentIdx + 1) % len(opponents)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		*g = *NewPuzzleGame(0)
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		*g = *NewEditorGame()
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		*g = *NewLessonGame(0)
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.Key1) {
+		*g = *startGame(5, 1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.Key2) {
+		*g = *startGame(50, 5)
+	}
+	if mx, my, ok := pointerJustPressed(); ok {
+		items := menuItems(stakesMenu)
+		if i := menuItemAt(items, mx, my); i >= 0 {
+			items[i].choose(g)
+		}
+	}
+}
+
+// updateNameEntry types the name of a new profile; Enter creates it and
+// Escape backs out.
+func (g *Game) updateNameEntry() {
+	for _, r := range ebiten.AppendInputChars(nil) {
+		r = unicode.ToUpper(r)
+		if validProfileName(r) && len(g.nameEntry) < 12 {
+			g.nameEntry += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(g.nameEntry) > 0 {
+		g.nameEntry = g.nameEntry[:len(g.nameEntry)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && g.nameEntry != "" {
+		profile = loadProfile(g.nameEntry)
+		profile.save()
+		g.enteringName = false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.enteringName = false
+	}
+}
+
+// The promotion popup offers these pieces as sprites drawn at double size,
+// left to right from promoX, so they're easy to hit with a finger.
+var promoChoices = []PieceType{Queen, Rook, Bishop, Knight}
 
 const (
-	Pawn PieceType = iota
-	Bishop
-	Rook
-	Knight
-	Queen
-	King
+	promoX, promoY = 14, 64
+	promoStep      = 34
+	promoSize      = 2 * tileSize
 )
 
-// Piece values for Frank's brain
-var pieceValues = map[PieceType]int{
-	Pawn: 1, Knight: 3, Bishop: 3, Rook: 5, Queen: 9, King: 100,
+// promoChoiceAt returns the promotion choice under a canvas position.
+func promoChoiceAt(x, y int) (PieceType, bool) {
+	if y < promoY || y >= promoY+promoSize || x < promoX {
+		return 0, false
+	}
+	i := (x - promoX) / promoStep
+	if i >= len(promoChoices) || (x-promoX)%promoStep >= promoSize {
+		return 0, false
+	}
+	return promoChoices[i], true
 }
 
-type ChessPiece struct {
-	Type     PieceType
-	Color    Color
-	SpriteID int
-	HasMoved bool
+func (g *Game) updatePromotion() {
+	if mx, my, ok := pointerJustPressed(); ok {
+		if t, ok := promoChoiceAt(mx, my); ok {
+			g.promote(t)
+			return
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyQ):
+		g.promote(Queen)
+	case inpututil.IsKeyJustPressed(ebiten.KeyR):
+		g.promote(Rook)
+	case inpututil.IsKeyJustPressed(ebiten.KeyB):
+		g.promote(Bishop)
+	case inpututil.IsKeyJustPressed(ebiten.KeyN):
+		g.promote(Knight)
+	}
 }
 
-var sprites []*ebiten.Image
-var wallet = 100
-
-type Game struct {
-	board                [8][8]*ChessPiece
-	selectedX, selectedY int
-	activeColor          Color
-	hustlerName          string
-	currentDialog        string
-	whiteTime, blackTime float64
-	gameOver             bool
-	gameStarted          bool
-	wager                int
-	initialMins          int
-	rng                  *rand.Rand
-	epX, epY             int
-	winner               int
-	moveCount            int
-	frankThinkTime       int
-	promoting            bool
-	promX, promY         int
-}
-
-func NewGame(wager int, minutes int) *Game {
-	g := &Game{
-		selectedX: -1, selectedY: -1, epX: -1, epY: -1,
-		activeColor:   White,
-		hustlerName:   "4-Move-Frank",
-		currentDialog: "Eyes on the board, kid.",
-		whiteTime:     float64(minutes * 60 * 60),
-		blackTime:     float64(minutes * 60 * 60),
-		wager:         wager,
-		gameStarted:   true,
-		initialMins:   minutes,
-		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
-		winner:        -1,
-	}
-	g.setupBoard()
-	return g
+// renderSquare is the canvas position of a board square's top-left corner.
+// Every board drawing and click goes through it and boardSquare, so flipping
+// the board for a player on the Black side only has to happen here.
+func (g *Game) renderSquare(bx, by int) (int, int) {
+	if g.flipped {
+		bx, by = 7-bx, 7-by
+	}
+	return (bx + 1) * tileSize, (by + 1) * tileSize
 }
 
-func (g *Game) setupBoard() {
-	layout := []PieceType{Rook, Knight, Bishop, Queen, King, Bishop, Knight, Rook}
-	for i := 0; i < 8; i++ {
-		g.createPiece(layout[i], Black, i, 0)
-		g.createPiece(Pawn, Black, i, 1)
-		g.createPiece(Pawn, White, i, 6)
-		g.createPiece(layout[i], White, i, 7)
+// boardSquare is the board square under a canvas position; ok is false off
+// the board, including on its border.
+func (g *Game) boardSquare(x, y int) (bx, by int, ok bool) {
+	if x < tileSize || y < tileSize {
+		return 0, 0, false
+	}
+	bx, by = x/tileSize-1, y/tileSize-1
+	if bx > 7 || by > 7 {
+		return 0, 0, false
 	}
+	if g.flipped {
+		bx, by = 7-bx, 7-by
+	}
+	return bx, by, true
 }
 
-func (g *Game) createPiece(t PieceType, c Color, x, y int) {
-	sid := int(t)
-	if c == White {
-		sid += 6
+// clickBoard selects a piece of the side to move, or moves the selected one, at a canvas
+// position. With ConfirmMoves on, the first click on a destination only
+// previews the move and any other click cancels it.
+func (g *Game) clickBoard(mx, my int) {
+	gx, gy, ok := g.boardSquare(mx, my)
+	if !ok {
+		return
+	}
+	if g.selectedX == -1 {
+		if g.board[gy][gx] != nil && g.board[gy][gx].Color == g.activeColor {
+			g.selectedX, g.selectedY = gx, gy
+		}
+		return
+	}
+	p := g.board[g.selectedY][g.selectedX]
+	m := Move{From: Pos{g.selectedX, g.selectedY}, To: Pos{gx, gy}}
+	legal := g.isMoveLegal(p, m) && g.isMoveSafe(m)
+	if q := g.board[gy][gx]; !legal && q != nil && q.Color == g.activeColor && q != p {
+		// Another of our own pieces: switch to it rather than drop the
+		// selection. Checked after legality, as a Chess960 king castles by
+		// moving onto its own rook.
+		g.selectedX, g.selectedY = gx, gy
+		g.pendingX, g.pendingY = -1, -1
+		return
+	}
+	if legal && profile.Settings.ConfirmMoves && (gx != g.pendingX || gy != g.pendingY) {
+		// Show a ghost first; only a second click on the same square moves.
+		g.pendingX, g.pendingY = gx, gy
+		return
+	}
+	if legal {
+		g.readAutoQueen()
+		g.playerMove(m)
 	}
-	g.board[y][x] = &ChessPiece{Type: t, Color: c, SpriteID: sid, HasMoved: false}
+	g.selectedX, g.selectedY = -1, -1
+	g.pendingX, g.pendingY = -1, -1
 }
 
-func toAlg(x, y int) string { return fmt.Sprintf("%c%d", 'a'+x, 8-y) }
-func pName(p *ChessPiece) string {
-	return []string{"Pawn", "Bishop", "Rook", "Knight", "Queen", "King"}[p.Type]
+// The editor palette fills the HUD: Black's pieces on the top row and
+// White's below, then an eraser and the side-to-move switch to the right.
+const (
+	paletteX = 4
+	paletteY = gridSize*tileSize + 3
+	sideX    = paletteX + 6*tileSize + 4
+)
+
+// paletteAt returns the palette pick under a canvas position.
+func paletteAt(x, y int) (sprite int, ok bool) {
+	row := (y - paletteY) / (tileSize + 2)
+	if y < paletteY || row > 1 || (y-paletteY)%(tileSize+2) >= tileSize {
+		return 0, false
+	}
+	if x >= paletteX && x < paletteX+6*tileSize {
+		return row*6 + (x-paletteX)/tileSize, true
+	}
+	if x >= sideX && x < sideX+tileSize && row == 0 {
+		return -1, true
+	}
+	return 0, false
 }
-func abs(v int) int {
-	if v < 0 {
-		return -v
+
+// updateEditor runs the board editor: pick from the palette and click squares
+// to place pieces, Tab or the side button to change who moves, C to clear and
+// S to save the position as a .fen file. Enter checks the position and plays
+// it against Frank.
+func (g *Game) updateEditor() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		*g = Game{gameStarted: false}
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		g.activeColor = 1 - g.activeColor
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		g.clearEdit()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		if fen, err := g.editedFEN(); err != nil {
+			g.currentDialog = err.Error()
+		} else if _, err := saveFEN(fen); err != nil {
+			g.currentDialog = "COULDN'T SAVE"
+		} else {
+			g.currentDialog = "SAVED AS .FEN"
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		fen, err := g.finishEdit()
+		if err != nil {
+			g.currentDialog = err.Error()
+			return
+		}
+		if verboseLog {
+			fmt.Println("FEN:", fen)
+		}
+		g.seat(&opponents[opponentIdx])
+		return
+	}
+	mx, my, ok := pointerJustPressed()
+	if !ok {
+		return
+	}
+	if gx, gy, ok := g.boardSquare(mx, my); ok {
+		g.editSquare(gx, gy)
+	} else if s, ok := paletteAt(mx, my); ok {
+		g.editSprite = s
+	} else if mx >= sideX && my >= paletteY+tileSize+2 {
+		g.activeColor = 1 - g.activeColor
 	}
-	return v
 }
 
-func (g *Game) isPathClear(fx, fy, tx, ty int) bool {
-	dx, dy := tx-fx, ty-fy
-	sx, sy := 0, 0
-	if dx != 0 {
-		sx = dx / abs(dx)
+// playerInput takes the player's move: clicks on the board or the on-screen
+// pad, or Enter to type one in SAN or long algebraic and Enter again to play
+// it.
+func (g *Game) playerInput() {
+	if g.typingMove {
+		g.updateMoveEntry()
+		return
 	}
-	if dy != 0 {
-		sy = dy / abs(dy)
+	if mx, my, ok := pointerJustPressed(); g.showsPad() && g.updatePad(mx, my, ok) {
+		return
 	}
-	cx, cy := fx+sx, fy+sy
-	for cx != tx || cy != ty {
-		if g.board[cy][cx] != nil {
-			return false
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) && g.selectedX >= 0 {
+		g.cycleCandidate(ebiten.IsKeyPressed(ebiten.KeyShift))
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		if g.selectedX >= 0 && g.pendingX >= 0 {
+			g.readAutoQueen()
+			g.playerMove(Move{From: Pos{g.selectedX, g.selectedY}, To: Pos{g.pendingX, g.pendingY}})
+			g.selectedX, g.selectedY = -1, -1
+			g.pendingX, g.pendingY = -1, -1
+			return
 		}
-		cx += sx
-		cy += sy
+		g.typingMove, g.moveEntry = true, ""
+		return
 	}
-	return true
+	if mx, my, ok := pointerJustPressed(); ok {
+		g.clickBoard(mx, my)
+		g.pressDrag(mx, my)
+		return
+	}
+	g.updateDrag()
 }
 
-func (g *Game) isMoveLegal(p *ChessPiece, fx, fy, tx, ty int) bool {
-	if tx < 0 || tx > 7 || ty < 0 || ty > 7 {
-		return false
+// cycleCandidate moves the ghost of the selected piece on to its next legal
+// destination, or its previous one with back set, so that Enter can play a
+// move without the mouse. It reuses the ghost ConfirmMoves shows, and a
+// click on it plays the move too.
+func (g *Game) cycleCandidate(back bool) {
+	from := Pos{g.selectedX, g.selectedY}
+	var dests []Pos
+	for _, m := range g.LegalMoves(g.activeColor) {
+		if m.From == from {
+			dests = append(dests, m.To)
+		}
 	}
-	target := g.board[ty][tx]
-	if target != nil && target.Color == p.Color {
-		return false
+	if len(dests) == 0 {
+		return
 	}
-	dx, dy := abs(tx-fx), abs(ty-fy)
-
-	switch p.Type {
-	case Knight:
-		return (dx == 2 && dy == 1) || (dx == 1 && dy == 2)
-	case Rook:
-		return (fx == tx || fy == ty) && g.isPathClear(fx, fy, tx, ty)
-	case Bishop:
-		return dx == dy && g.isPathClear(fx, fy, tx, ty)
-	case Queen:
-		return (dx == dy || fx == tx || fy == ty) && g.isPathClear(fx, fy, tx, ty)
-	case King:
-		if dx <= 1 && dy <= 1 {
-			return true
-		}
-		if p.HasMoved || fy != ty || dx != 2 || g.isInCheck(p.Color) {
-			return false
-		}
-		rx := 0
-		if tx > fx {
-			rx = 7
-		}
-		rook := g.board[fy][rx]
-		return rook != nil && rook.Type == Rook && !rook.HasMoved && g.isPathClear(fx, fy, rx, fy)
-	case Pawn:
-		dir := -1
-		if p.Color == Black {
-			dir = 1
-		}
-		if fx == tx && ty == fy+dir && target == nil {
-			return true
-		}
-		if fx == tx && ty == fy+2*dir && fy == (map[Color]int{White: 6, Black: 1}[p.Color]) && target == nil && g.isPathClear(fx, fy, tx, ty) {
-			return true
-		}
-		if dx == 1 && ty == fy+dir {
-			if target != nil || (tx == g.epX && ty == g.epY) {
-				return true
-			}
+	i, step := slices.Index(dests, Pos{g.pendingX, g.pendingY}), 1
+	if back {
+		step = len(dests) - 1
+		if i < 0 {
+			i = 0
 		}
 	}
-	return false
+	next := dests[(i+step)%len(dests)]
+	g.pendingX, g.pendingY = next.X, next.Y
 }
 
-func (g *Game) isInCheck(c Color) bool {
-	kx, ky := -1, -1
-	for y := 0; y < 8; y++ {
-		for x := 0; x < 8; x++ {
-			if p := g.board[y][x]; p != nil && p.Type == King && p.Color == c {
-				kx, ky = x, y
-			}
+// updateMoveEntry types a move into the HUD. Escape backs out; on Enter a
+// move that doesn't parse is reported and dropped.
+func (g *Game) updateMoveEntry() {
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-=+#", r)) && len(g.moveEntry) < 8 {
+			g.moveEntry += string(r)
 		}
 	}
-	if kx == -1 {
-		return false
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(g.moveEntry) > 0 {
+		g.moveEntry = g.moveEntry[:len(g.moveEntry)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.typingMove = false
+	}
+	if !inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		return
+	}
+	g.typingMove = false
+	m, err := g.parseMove(g.moveEntry)
+	if err != nil {
+		g.currentDialog = err.Error()
+		return
 	}
-	return g.isSquareAttacked(kx, ky, 1-c)
+	g.selectedX, g.selectedY = -1, -1
+	g.pendingX, g.pendingY = -1, -1
+	g.readAutoQueen()
+	g.playerMove(m)
 }
 
-func (g *Game) isSquareAttacked(x, y int, attackerColor Color) bool {
-	for fy := 0; fy < 8; fy++ {
-		for fx := 0; fx < 8; fx++ {
-			p := g.board[fy][fx]
-			if p != nil && p.Color == attackerColor {
-				if g.isMoveLegal(p, fx, fy, x, y) {
-					return true
-				}
+// readAutoQueen notes, as the player makes or queues a move, whether a
+// promotion should skip the piece picker: the AutoQueen setting is on and
+// Shift isn't held to underpromote.
+func (g *Game) readAutoQueen() {
+	g.autoQueen = profile.Settings.AutoQueen && !ebiten.IsKeyPressed(ebiten.KeyShift)
+}
+
+// updatePuzzle runs puzzle mode: no clocks or money, and Frank answers from
+// the puzzle's solution. An even puzzleStep means the player is to move, so
+// Black to move on an even step means the player's move is waiting to be judged.
+func (g *Game) updatePuzzle() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && !g.typingMove {
+		*g = Game{gameStarted: false}
+		return
+	}
+	if g.puzzleDone {
+		if _, _, ok := pointerJustPressed(); ok || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			next := g.puzzleIdx
+			if g.puzzleSolved {
+				next = (next + 1) % len(puzzles)
 			}
+			*g = *NewPuzzleGame(next)
+		}
+		return
+	}
+	if g.promoting {
+		g.updatePromotion()
+		return
+	}
+	if g.activeColor == White {
+		g.playerInput()
+		return
+	}
+	if g.puzzleStep%2 == 0 {
+		g.judgePuzzleMove()
+		return
+	}
+	g.frankThinkTime++
+	if g.frankThinkTime >= 30 {
+		if m, ok := g.puzzleReply(); ok {
+			g.executeMove(m)
 		}
 	}
-	return false
 }
 
-func (g *Game) hasLegalMoves(c Color) bool {
-	for fy := 0; fy < 8; fy++ {
-		for fx := 0; fx < 8; fx++ {
-			p := g.board[fy][fx]
-			if p == nil || p.Color != c {
-				continue
+// updateLesson runs a mating lesson: no clocks or money, the board shows the
+// player's best moves, and Frank runs his king for the middle. Mate or
+// stalemate ends it, and a click or Enter goes on to the next lesson after a
+// mate or sets this one up again after a stalemate.
+func (g *Game) updateLesson() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && !g.typingMove {
+		*g = Game{gameStarted: false}
+		return
+	}
+	if g.lessonDone {
+		if _, _, ok := pointerJustPressed(); ok || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			next := g.lessonIdx
+			if g.isInCheck(Black) {
+				next = (next + 1) % len(lessons)
 			}
-			for ty := 0; ty < 8; ty++ {
-				for tx := 0; tx < 8; tx++ {
-					if g.isMoveLegal(p, fx, fy, tx, ty) {
-						orig := g.board[ty][tx]
-						g.board[ty][tx], g.board[fy][fx] = p, nil
-						safe := !g.isInCheck(c)
-						g.board[fy][fx], g.board[ty][tx] = p, orig
-						if safe {
-							return true
-						}
-					}
-				}
+			*g = *NewLessonGame(next)
+		}
+		return
+	}
+	if g.activeColor == White {
+		g.playerInput()
+		if g.activeColor == Black && !g.hasLegalMoves(Black) {
+			g.lessonDone = true
+		}
+		return
+	}
+	g.frankThinkTime++
+	if g.frankThinkTime >= 30 {
+		if m, ok := g.lessonDefence(); ok {
+			g.currentDialog = ""
+			if g.board[m.To.Y][m.To.X] != nil {
+				g.currentDialog = "Free piece, thanks!"
 			}
+			g.executeMove(m)
+			g.coachLesson()
 		}
 	}
-	return false
 }
 
-func (g *Game) executeMove(fx, fy, tx, ty int) {
-	p := g.board[fy][fx]
-	fmt.Printf("[%d] %s: %s -> %s\n", p.Color, pName(p), toAlg(fx, fy), toAlg(tx, ty))
+// bulletMins is the longest starting clock that counts as bullet. Those
+// games keep running when the window loses focus, so the player can't stop
+// the clock to think it over in another window.
+const bulletMins = 1
 
-	if p.Type == King && abs(tx-fx) == 2 {
-		rx, rtx := 0, 3
-		if tx > fx {
-			rx, rtx = 7, 5
-		}
-		rook := g.board[fy][rx]
-		g.board[fy][rtx], g.board[fy][rx] = rook, nil
-		rook.HasMoved = true
+// focusPaused reports whether play is held because the window isn't focused.
+// Skipping Update's turn logic, tickClock included, stops the clocks dead.
+func (g *Game) focusPaused() bool {
+	return g.initialMins > bulletMins && !ebiten.IsFocused()
+}
+
+// maxTickGap caps the time one Update can charge to a clock, in frames, so
+// a machine waking from sleep doesn't flag whoever was to move.
+const maxTickGap = 5 * ticksPerSecond
+
+var lastTick time.Time // when takeTicks last ran
+
+// takeTicks is the time since it last ran, in frames. The clocks run on it
+// rather than counting Updates, which can stall: Ebitengine only catches up
+// on short delays, and Frank's search can hold up an Update for longer.
+func takeTicks() float64 {
+	now := time.Now()
+	dt := 1.0
+	if !lastTick.IsZero() {
+		dt = min(now.Sub(lastTick).Seconds()*ticksPerSecond, maxTickGap)
 	}
+	lastTick = now
+	return dt
+}
 
-	if p.Type == Pawn && tx == g.epX && ty == g.epY {
-		g.board[fy][tx] = nil
+// tickClock runs the side to move's clock down by dt frames and reports
+// whether it ran out, which ends the game. Only the players at the board get
+// the low-time warning.
+func (g *Game) tickClock(dt float64) bool {
+	clock := &g.whiteTime
+	if g.activeColor == Black {
+		clock = &g.blackTime
 	}
-	g.epX, g.epY = -1, -1
-	if p.Type == Pawn && abs(ty-fy) == 2 {
-		g.epX, g.epY = fx, fy+(ty-fy)/2
+	*clock -= dt
+	if g.activeColor == White || g.hotseat {
+		g.warnLowTime(*clock, dt)
 	}
+	if *clock > 0 {
+		return false
+	}
+	g.flagFall(g.activeColor)
+	return true
+}
 
-	g.board[ty][tx], g.board[fy][fx] = p, nil
-	p.HasMoved = true
+// endGame ends the game for a reason found on the board, with the hustler's
+// last word on it.
+func (g *Game) endGame(reason string, winner int) {
+	drawn := winner < 0
+	winner = g.decisive(winner)
+	g.gameOver, g.endReason, g.winner = true, reason, winner
+	switch {
+	case g.hotseat:
+	case winner == 0:
+		g.currentDialog = g.rival().Mates
+	case winner == 1:
+		g.currentDialog = g.rival().Mated
+	case reason == endDead:
+		g.currentDialog = "Dead board. Call it a draw."
+	case reason == endFiftyMove || reason == endRepetition:
+		g.currentDialog = "Going nowhere. Call it a draw."
+	case reason == endPerpetual:
+		g.currentDialog = "Check, check, check. A draw."
+	}
+	if drawn && winner >= 0 && !g.hotseat {
+		g.currentDialog = "Draw's a win for me. Pay up."
+	}
+	g.bookResult()
+}
 
-	if p.Type == Pawn && (ty == 0 || ty == 7) {
-		if p.Color == White {
-			g.promoting = true
-			g.promX, g.promY = tx, ty
-		} else {
-			p.Type = Queen
-			p.SpriteID = 4
+// canClaimPerpetual reports whether a player at the board is giving
+// perpetual check, and so may settle the game as a draw with D.
+func (g *Game) canClaimPerpetual() bool {
+	checker, ok := g.perpetualCheck()
+	return ok && !g.gameOver && g.puzzle == nil && !g.lesson && g.humanSide(checker)
+}
+
+// frankClaimsPerpetual reports whether Frank, giving perpetual check, takes
+// the draw: he does when his last search had him worse.
+func (g *Game) frankClaimsPerpetual() bool {
+	checker, ok := g.perpetualCheck()
+	return ok && checker == Black && !g.hotseat && len(g.evals) > 0 && g.evals[len(g.evals)-1] > 0
+}
+
+// flagFall ends the game on c's clock running out: a loss for c, unless the
+// other side could never mate, which makes it a draw.
+func (g *Game) flagFall(c Color) {
+	g.gameOver, g.winner, g.endReason = true, int(1-c), endTime
+	if g.timeoutIsDraw(c) {
+		g.winner, g.currentDialog = g.decisive(-1), "Time's up, but nobody mates. Push."
+		if g.winner >= 0 && !g.hotseat {
+			g.currentDialog = "Nobody mates. Draw's a win for me."
 		}
 	}
-	if !g.promoting {
-		g.activeColor = 1 - g.activeColor
+	g.bookResult()
+}
+
+// updateHotseat runs a hot-seat game: whoever is to move plays. A typed
+// move's error stays up until the next move.
+func (g *Game) updateHotseat() {
+	side := g.activeColor
+	g.playerInput()
+	if g.activeColor != side {
+		g.currentDialog = ""
 	}
-	g.moveCount++
-	g.frankThinkTime = 0
+}
+
+// buyTakeback sells the player a takeback in a staked game, if one is on
+// offer and the wallet covers it.
+func (g *Game) buyTakeback() {
+	if g.wager == 0 || !g.canTakeBack() {
+		return
+	}
+	if profile.Wallet < takebackCost {
+		g.currentDialog = "No cash, no takebacks."
+		return
+	}
+	profile.Wallet -= takebackCost
+	profile.save()
+	g.takeBack()
+	g.takebacks++
+	g.currentDialog = "Ten bucks. Go again."
+}
+
+// rewindBlunder takes the player's blunder back for free, with easy assist
+// on. It doesn't count against the game's bought takebacks.
+func (g *Game) rewindBlunder() {
+	if !g.canRewind() {
+		return
+	}
+	g.takeBack()
+	g.currentDialog = "Fine. Look again."
+}
+
+// bookResult settles a finished game's stakes and side bet and, with
+// -verbose, prints it as PGN.
+func (g *Game) bookResult() {
+	profile.settle(g.winner, g.wager, g.payout(), g.rated)
+	g.settleBet()
+	g.keepForReview()
+	if verboseLog {
+		fmt.Print("\n", g.PGN(profile.Name))
+	}
+}
+
+// screenState identifies the screen on view, for noticing when it changes.
+type screenState struct {
+	started, over bool
+	menu          menuScreen
+}
+
+func (g *Game) shownScreen() screenState {
+	return screenState{g.gameStarted, g.gameOver, g.currentMenu()}
 }
 
 func (g *Game) Update() error {
-	if !g.gameStarted {
-		if inpututil.IsKeyJustPressed(ebiten.Key1) {
-			*g = *NewGame(5, 1)
-		}
-		if inpututil.IsKeyJustPressed(ebiten.Key2) {
-			*g = *NewGame(50, 5)
+	dt := takeTicks()
+	if pointerHold > 0 {
+		pointerHold--
+	}
+	shown := g.shownScreen()
+	defer func() {
+		if g.shownScreen() != shown {
+			pointerHold = pointerHoldFrames
 		}
+	}()
+	if inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+		g.saveScreenshot()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		debugHUD = !debugHUD
+	}
+	if err := g.updateQuit(); err != nil || g.confirmQuit {
+		return err
+	}
+	g.updateDrop()
+	useSpriteSheet(profile.Settings.PieceSet)
+	if !g.gameStarted {
+		g.updateMenu()
 		return nil
 	}
-	if g.gameOver {
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			*g = *NewGame(g.wager, g.initialMins)
+	if g.editing {
+		g.updateEditor()
+		return nil
+	}
+	g.tickAnimations()
+	if g.countdown > 0 {
+		g.countdown--
+		if line := stakesLine(g.wager); g.countdown == 0 && line != "" && g.currentDialog == g.rival().Greeting {
+			g.currentDialog = line
 		}
 		return nil
 	}
-	if g.promoting {
-		if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
-			g.board[g.promY][g.promX].Type, g.board[g.promY][g.promX].SpriteID = Queen, 10
-			g.promoting = false
+	if !g.typingMove {
+		if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+			g.showHistory = !g.showHistory
 		}
-		if inpututil.IsKeyJustPressed(ebiten.KeyR) {
-			g.board[g.promY][g.promX].Type, g.board[g.promY][g.promX].SpriteID = Rook, 8
-			g.promoting = false
+		if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+			verboseLog = !verboseLog
 		}
-		if inpututil.IsKeyJustPressed(ebiten.KeyB) {
-			g.board[g.promY][g.promX].Type, g.board[g.promY][g.promX].SpriteID = Bishop, 7
-			g.promoting = false
+		if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+			instantFrank = !instantFrank
 		}
-		if inpututil.IsKeyJustPressed(ebiten.KeyN) {
-			g.board[g.promY][g.promX].Type, g.board[g.promY][g.promX].SpriteID = Knight, 9
-			g.promoting = false
+		if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+			g.showThreats = !g.showThreats
 		}
-		if !g.promoting {
-			g.activeColor = Black
+		if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+			g.saveSnapshot()
 		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+			g.copyMoves()
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyB) && !g.promoting {
+			g.blindfold = !g.blindfold
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+			profile.Settings.HideMoney = !profile.Settings.HideMoney
+			profile.save()
+		}
+	}
+	if g.puzzle != nil {
+		g.updatePuzzle()
 		return nil
 	}
-	if !g.hasLegalMoves(g.activeColor) {
-		g.gameOver = true
-		if g.isInCheck(g.activeColor) {
-			if g.activeColor == White {
-				g.winner, g.currentDialog = 0, "MATE! Give me my money."
-				wallet -= g.wager
-			} else {
-				g.winner, g.currentDialog = 1, "MATE! Take the cash."
-				wallet += g.wager
+	if g.lesson {
+		g.updateLesson()
+		return nil
+	}
+	if g.gameOver {
+		if g.postMortem != nil {
+			g.updatePostMortem()
+			return nil
+		}
+		if g.navigateMenu() {
+			return nil
+		}
+		if mx, my, ok := pointerJustPressed(); ok {
+			items := menuItems(gameOverMenu)
+			i := menuItemAt(items, mx, my)
+			if i < 0 {
+				i = 0 // a tap anywhere else plays again
 			}
+			items[i].choose(g)
 		}
 		return nil
 	}
+	if g.promoting {
+		g.updatePromotion()
+		return nil
+	}
+	paused := g.focusPaused()
+	if g.judge(dt, paused) || paused {
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) && !g.typingMove && g.canClaimPerpetual() || g.frankClaimsPerpetual() {
+		g.endGame(endPerpetual, -1)
+		return nil
+	}
+	if g.hotseat {
+		g.updateHotseat()
+		return nil
+	}
 
 	if g.activeColor == White {
-		g.whiteTime--
-		if g.whiteTime <= 0 {
-			g.gameOver, g.winner, wallet = true, 0, wallet-g.wager
-		}
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			mx, my := ebiten.CursorPosition()
-			gx, gy := (mx/tileSize)-1, (my/tileSize)-1
-			if gx >= 0 && gx < 8 && gy >= 0 && gy < 8 {
-				if g.selectedX == -1 {
-					if g.board[gy][gx] != nil && g.board[gy][gx].Color == White {
-						g.selectedX, g.selectedY = gx, gy
-					}
-				} else {
-					p := g.board[g.selectedY][g.selectedX]
-					if g.isMoveLegal(p, g.selectedX, g.selectedY, gx, gy) {
-						orig := g.board[gy][gx]
-						g.board[gy][gx], g.board[g.selectedY][g.selectedX] = p, nil
-						if !g.isInCheck(White) {
-							g.board[g.selectedY][g.selectedX], g.board[gy][gx] = p, orig
-							g.executeMove(g.selectedX, g.selectedY, gx, gy)
-						} else {
-							g.board[g.selectedY][g.selectedX], g.board[gy][gx] = p, orig
-						}
-					}
-					g.selectedX, g.selectedY = -1, -1
-				}
+		if g.betPending && !g.typingMove {
+			g.updateBetOffer()
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyU) && !g.typingMove {
+			if g.canRewind() {
+				g.rewindBlunder()
+			} else {
+				g.buyTakeback()
 			}
 		}
+		g.playerInput()
 	} else {
-		g.blackTime--
-		if g.blackTime <= 0 {
-			g.gameOver, g.winner, wallet = true, 1, wallet+g.wager
+		g.betPending = false // the player moved instead of answering
+		if mx, my, ok := pointerJustPressed(); ok {
+			if gx, gy, ok := g.boardSquare(mx, my); ok {
+				g.readAutoQueen()
+				g.queuePremove(gx, gy)
+			}
+		}
+		if g.frankThinkTime == 0 {
+			g.thinkLimit = g.thinkFrames(Black) * thinkPace / 100
 		}
 		g.frankThinkTime++
-		limit := 180
-		if g.moveCount < 6 {
-			limit = 60
-		} else if g.moveCount < 16 {
-			limit = 120
-		}
-		if g.frankThinkTime >= limit {
-			// SCHOLAR'S MATE (STILL PRIORITIZED)
-			script := [][]int{{4, 1, 4, 3}, {3, 0, 7, 4}, {5, 0, 2, 3}, {7, 4, 5, 6}}
-			for _, m := range script {
-				if p := g.board[m[1]][m[0]]; p != nil && p.Color == Black && g.isMoveLegal(p, m[0], m[1], m[2], m[3]) {
-					orig := g.board[m[3]][m[2]]
-					g.board[m[3]][m[2]], g.board[m[1]][m[0]] = p, nil
-					if !g.isInCheck(Black) {
-						g.board[m[1]][m[0]], g.board[m[3]][m[2]] = p, orig
-						g.executeMove(m[0], m[1], m[2], m[3])
-						return nil
-					}
-					g.board[m[1]][m[0]], g.board[m[3]][m[2]] = p, orig
+		if g.frankThinkTime >= g.thinkLimit || instantFrank {
+			if m, score, ok := g.frankSearch(Black); ok {
+				// the search's own time is his, not the player's next turn's
+				if g.tickClock(takeTicks()) {
+					return nil
 				}
+				g.evals = append(g.evals, -score)
+				g.rewindOffer = profile.Settings.EasyAssist && g.punishes(m)
+				line := ""
+				if verboseLog {
+					line = g.lineText(g.lastSearch.pv, false)
+				}
+				g.executeMove(m)
+				if verboseLog {
+					fmt.Printf("    searched %d nodes to depth %d, best line: %s\n", g.lastSearch.nodes, g.lastSearch.depth, line)
+				}
+				g.offerBet()
+				g.playPremove()
 			}
+		}
+	}
+	return nil
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	if canvas == nil {
+		canvas = ebiten.NewImage(screenW, screenH)
+	}
+	canvas.Clear()
+	g.drawScene(canvas)
+	if debugHUD && g.gameStarted {
+		g.drawDebugHUD(canvas)
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(view.scale), float64(view.scale))
+	op.GeoM.Translate(float64(view.x), float64(view.y))
+	screen.DrawImage(canvas, op)
+}
 
-			type move struct {
-				fx, fy, tx, ty int
-				score          int
+func (g *Game) drawScene(screen *ebiten.Image) {
+	if !g.gameStarted {
+		if g.tutorial != nil {
+			g.drawTutorial(screen)
+			return
+		}
+		if g.reviewBoard != nil {
+			g.drawReview(screen)
+			return
+		}
+		if g.demoGame != nil {
+			g.demoGame.drawBoard(screen)
+		}
+		vector.FillRect(screen, 10, 28, 140, 162, color.RGBA{0, 0, 0, 200}, false)
+		if g.showSettings {
+			g.drawSettings(screen)
+			return
+		}
+		ranked := color.RGBA{150, 150, 150, 255}
+		if rated {
+			ranked = color.RGBA{255, 215, 0, 255}
+		}
+		drawText(screen, "A:Rated", 20, stakesHeaderBaseline, ranked)
+		zerk := color.RGBA{150, 150, 150, 255}
+		if berserk {
+			zerk = color.RGBA{255, 50, 50, 255}
+		}
+		drawText(screen, "Z:Berserk", menuRightX, stakesHeaderBaseline, zerk)
+		hide := profile.Settings.HideMoney
+		for _, o := range menuOptions {
+			label := o.label
+			if hide {
+				label = withoutAmounts(label)
 			}
-			var smartMoves []move
-			for fy := 0; fy < 8; fy++ {
-				for fx := 0; fx < 8; fx++ {
-					if p := g.board[fy][fx]; p != nil && p.Color == Black {
-						for ty := 0; ty < 8; ty++ {
-							for tx := 0; tx < 8; tx++ {
-								if g.isMoveLegal(p, fx, fy, tx, ty) {
-									orig := g.board[ty][tx]
-
-									// CALC SCORE
-									score := 0
-									// Is the piece currently in danger?
-									inDanger := g.isSquareAttacked(fx, fy, White)
-									if inDanger {
-										score += pieceValues[p.Type] * 2 // Incentive to move piece out of danger
-									}
-									// Attack/Capture value
-									if orig != nil {
-										score += pieceValues[orig.Type] + 2
-									}
-
-									// TEST MOVE
-									g.board[ty][tx], g.board[fy][fx] = p, nil
-									if !g.isInCheck(Black) {
-										// Penalty for moving INTO danger
-										if g.isSquareAttacked(tx, ty, White) {
-											score -= pieceValues[p.Type] + 1
-										}
-										smartMoves = append(smartMoves, move{fx, fy, tx, ty, score})
-									}
-									g.board[fy][fx], g.board[ty][tx] = p, orig
-								}
-							}
-						}
-					}
+			drawText(screen, label, 20, o.baseline, color.RGBA{0, 255, 150, 255})
+		}
+		drawText(screen, "F:"+variants[variantIdx].name, 20, variantBaseline, color.White)
+		drawText(screen, "K:Odds "+materialOdds[oddsIdx].name, menuRightX, variantBaseline, color.White)
+		drawText(screen, "M:Puzzles", 20, puzzleBaseline, color.White)
+		drawText(screen, "E:Edit", menuRightX, puzzleBaseline, color.White)
+		drawText(screen, "L:Lessons", 20, lessonBaseline, color.White)
+		drawText(screen, "S: vs "+opponents[opponentIdx].Name, 20, styleBaseline, color.White)
+		drawText(screen, "O:Settings", 20, settingsBaseline, color.White)
+		drawText(screen, "H:Hotseat", menuRightX, settingsBaseline, color.White)
+		record := fmt.Sprintf("$%d W%d L%d D%d", profile.Wallet, profile.Wins, profile.Losses, profile.Draws)
+		if rated {
+			record = fmt.Sprintf("$%d RATED W%d L%d D%d", profile.Wallet, profile.RatedWins, profile.RatedLosses, profile.RatedDraws)
+		}
+		if hide {
+			record = withoutAmounts(record)
+		}
+		drawText(screen, record, 20, walletBaseline, color.RGBA{255, 215, 0, 255})
+		name, hint := profile.Name, "P:next N:new R:reset"
+		switch {
+		case g.enteringName:
+			name, hint = g.nameEntry+"_", "ENTER:ok ESC:back"
+		case g.confirmReset:
+			hint = "R again to reset!"
+		case g.menuNote != "":
+			hint = g.menuNote
+		}
+		drawText(screen, "PROFILE: "+name, 20, profileBaseline, color.RGBA{0, 255, 150, 255})
+		drawText(screen, hint, 15, profileBaseline+16, color.RGBA{150, 150, 150, 255})
+		g.drawMenuCursor(screen, 12)
+		return
+	}
+	if g.postMortem != nil {
+		g.drawPostMortem(screen)
+		return
+	}
+	g.drawBoard(screen)
+	if profile.Settings.VisualCues && g.cueFrames > 0 {
+		vector.StrokeRect(screen, 15, 15, 130, 130, 2, cueColors[g.lastCue], false)
+	}
+	dy := float32(gridSize * tileSize)
+	vector.FillRect(screen, 0, dy, 160, 40, color.RGBA{10, 10, 15, 255}, false)
+	if g.editing {
+		g.drawEditor(screen)
+		return
+	}
+	if g.puzzle != nil {
+		g.drawPuzzleHUD(screen, int(dy))
+	} else if g.lesson {
+		g.drawLessonHUD(screen, int(dy))
+	} else {
+		g.drawClocks(screen, int(dy))
+		stakes := fmt.Sprintf("$%d", g.wager)
+		if g.sideBet > 0 {
+			stakes += fmt.Sprintf("+%d", g.sideBet)
+		}
+		purse := fmt.Sprintf("STAKES:%s WALLET:$%d", stakes, profile.Wallet)
+		dialog := g.currentDialog
+		if profile.Settings.HideMoney {
+			purse = "PLAYER: " + profile.Name
+			if talksMoney(dialog) {
+				dialog = "..."
+			}
+		}
+		msg := g.hustlerName + ": " + dialog
+		if g.activeColor == Black && !g.gameOver {
+			msg = g.hustlerName + ": ..."
+		}
+		if g.hotseat {
+			purse, msg = "HOT SEAT: NO STAKES", dialog
+			if msg == "" && !g.gameOver {
+				msg = "WHITE TO MOVE"
+				if g.activeColor == Black {
+					msg = "BLACK TO MOVE"
 				}
 			}
+		}
+		if g.typingMove {
+			msg = "MOVE: " + g.moveEntry + "_"
+		}
+		if g.focusPaused() && !g.gameOver {
+			msg = "PAUSED"
+		}
+		if g.showsPad() {
+			g.drawPad(screen)
+		} else {
+			drawText(screen, purse, 5, int(dy)+24, color.RGBA{255, 215, 0, 255})
+			drawText(screen, msg, 5, int(dy)+36, color.White)
+		}
+	}
+	if g.betPending {
+		banner := g.betBanner()
+		if profile.Settings.HideMoney {
+			banner = "BET: MATE IN 10? Y/N"
+		}
+		drawText(screen, banner, 2, 12, color.RGBA{255, 215, 0, 255})
+	} else if g.canClaimPerpetual() {
+		drawText(screen, "PERPETUAL! D:DRAW", 3, 12, color.RGBA{255, 215, 0, 255})
+	} else if g.canRewind() {
+		drawText(screen, "BLUNDER! U:FREE UNDO", 3, 12, color.RGBA{255, 215, 0, 255})
+	} else if g.wager > 0 && g.canTakeBack() {
+		offer := fmt.Sprintf("U:TAKEBACK $%d (%d)", takebackCost, maxTakebacks-g.takebacks)
+		if profile.Settings.HideMoney {
+			offer = withoutAmounts(offer)
+		}
+		drawText(screen, offer, 3, 12, color.RGBA{255, 215, 0, 255})
+	} else if profile.Settings.DrawCounters && g.puzzle == nil && !g.lesson && !g.gameOver {
+		drawText(screen, g.drawCounters(), 3, 12, color.RGBA{150, 150, 150, 255})
+	}
+	if g.showHistory {
+		g.drawHistory(screen)
+	}
+	if g.promoting {
+		vector.FillRect(screen, 10, 40, 140, 80, color.RGBA{0, 0, 0, 230}, false)
+		drawText(screen, "PROMOTE:", 52, 57, color.White)
+		for i, t := range promoChoices {
+			x := promoX + i*promoStep
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(2, 2)
+			op.GeoM.Translate(float64(x), promoY)
+			screen.DrawImage(sprites[spriteID(t, g.activeColor)], op)
+			drawText(screen, pieceLetters[t], x+13, promoY+promoSize+13, color.RGBA{150, 150, 150, 255})
+		}
+	}
+	if g.gameOver {
+		vector.FillRect(screen, 0, 50, 160, 97, color.RGBA{0, 0, 0, 240}, false)
+		result := "DRAW"
+		switch {
+		case g.hotseat && g.winner == 0:
+			result = "BLACK WINS"
+		case g.hotseat && g.winner == 1:
+			result = "WHITE WINS"
+		case g.winner == 0:
+			result = g.rival().Short + " WINS"
+		case g.winner == 1:
+			result = "YOU WIN!"
+		}
+		drawText(screen, g.endReason, (screenW-7*len(g.endReason))/2, 75, color.RGBA{255, 50, 50, 255})
+		drawText(screen, result, (screenW-7*len(result))/2, 95, color.White)
+		drawText(screen, "PLAY AGAIN", 52, againBaseline, color.RGBA{0, 255, 150, 255})
+		drawText(screen, "MENU", 52, quitBaseline, color.RGBA{0, 255, 150, 255})
+		drawText(screen, "ANALYZE", 52, analyzeBaseline, color.RGBA{0, 255, 150, 255})
+		g.drawMenuCursor(screen, 42)
+		g.drawEvalGraph(screen)
+	}
+	if g.countdown > 0 {
+		g.drawCountdown(screen)
+	}
+	if g.confirmQuit {
+		g.drawConfirmQuit(screen)
+	}
+}
 
-			if len(smartMoves) > 0 {
-				best := smartMoves[0]
-				for _, m := range smartMoves {
-					if m.score > best.score {
-						best = m
-					}
-				}
-				g.executeMove(best.fx, best.fy, best.tx, best.ty)
+// drawClocks draws both clocks along the top of the HUD. The side to move has
+// its clock lit up and a swatch of its color at the end of the line, so whose
+// turn it is shows at a glance, even while Frank sits thinking. Over each
+// clock a bar shrinks with the share of its starting time left.
+func (g *Game) drawClocks(screen *ebiten.Image, dy int) {
+	clocks := []struct {
+		c    Color
+		t    float64
+		name string
+	}{{White, g.whiteTime, "W:"}, {Black, g.blackTime, "B:"}}
+	for i, k := range clocks {
+		x, clr := 5+i*56, color.Color(color.White)
+		if !g.gameOver {
+			if k.c == g.activeColor {
+				vector.FillRect(screen, float32(x-2), float32(dy+2), 53, 13, color.RGBA{60, 60, 90, 255}, false)
+			} else {
+				clr = color.RGBA{150, 150, 150, 255}
 			}
 		}
+		drawText(screen, fmt.Sprintf("%s%02d:%02d", k.name, int(k.t/(60*ticksPerSecond)), int(k.t/ticksPerSecond)%60), x, dy+12, clr)
+		if start := g.startTimes[k.c]; start > 0 {
+			left := min(max(k.t/start, 0), 1)
+			vector.FillRect(screen, float32(x-2), float32(dy), float32(53*left), 2, timeBarColor(left), false)
+		}
 	}
-	return nil
+	if g.increment > 0 {
+		mode := "+"
+		if g.bronstein {
+			mode = "d"
+		}
+		drawText(screen, fmt.Sprintf("%s%ds", mode, int(g.increment/ticksPerSecond)), 117, dy+12, color.White)
+	}
+	if g.gameOver {
+		return
+	}
+	swatch := color.RGBA{240, 240, 240, 255}
+	if g.activeColor == Black {
+		swatch = color.RGBA{20, 20, 20, 255}
+	}
+	vector.FillRect(screen, 147, float32(dy+3), 9, 9, swatch, false)
+	vector.StrokeRect(screen, 147, float32(dy+3), 9, 9, 1, color.RGBA{150, 150, 150, 255}, false)
 }
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	if !g.gameStarted {
-		text.Draw(screen, "CHOOSE STAKES:", basicfont.Face7x13, 20, 60, color.White)
-		text.Draw(screen, "1: $5 Bullet | 2: $50 Blitz", basicfont.Face7x13, 20, 90, color.RGBA{0, 255, 150, 255})
-		text.Draw(screen, fmt.Sprintf("WALLET: $%d", wallet), basicfont.Face7x13, 20, 120, color.RGBA{255, 215, 0, 255})
+// timeBarColor shades a time bar by the share of the clock left: green when
+// full, through yellow at half, to red when it runs out.
+func timeBarColor(left float64) color.RGBA {
+	if left > 0.5 {
+		return color.RGBA{uint8(510 * (1 - left)), 200, 0, 255}
+	}
+	return color.RGBA{255, uint8(400 * left), 0, 255}
+}
+
+func (g *Game) drawSettings(screen *ebiten.Image) {
+	drawText(screen, fmt.Sprintf("SETTINGS %d/%d:", settingsPage+1, settingsPages()), 20, 42, color.White)
+	lo, hi := settingsOnPage()
+	for i := lo; i < hi; i++ {
+		r := settingsRows[i]
+		drawText(screen, fmt.Sprintf("%d: %s %s", (i%settingsPerPage+1)%10, r.label, r.value()), 20, settingsRowBaseline(i), color.RGBA{0, 255, 150, 255})
+	}
+	drawText(screen, "ESC: back", 20, settingsBackBaseline, color.RGBA{150, 150, 150, 255})
+	drawText(screen, "TAB: more", 90, settingsBackBaseline, color.RGBA{150, 150, 150, 255})
+	g.drawMenuCursor(screen, 12)
+}
+
+func (g *Game) drawPuzzleHUD(screen *ebiten.Image, dy int) {
+	title := fmt.Sprintf("PUZZLE %d/%d: MATE IN %d", g.puzzleIdx+1, len(puzzles), g.puzzle.MateIn)
+	if g.puzzle.MateIn == 0 {
+		title = fmt.Sprintf("PUZZLE %d/%d R%d", g.puzzleIdx+1, len(puzzles), g.puzzle.Rating)
+	}
+	drawText(screen, title, 5, dy+12, color.White)
+	if g.puzzleDone && !g.puzzleSolved {
+		drawText(screen, "ANSWER: "+g.puzzleAnswer, 5, dy+24, color.RGBA{255, 215, 0, 255})
+	} else {
+		drawText(screen, g.puzzle.Name, 5, dy+24, color.RGBA{255, 215, 0, 255})
+	}
+	msg := g.currentDialog
+	if g.typingMove {
+		msg = "MOVE: " + g.moveEntry + "_"
+	}
+	drawText(screen, msg, 5, dy+36, color.White)
+}
+
+func (g *Game) drawLessonHUD(screen *ebiten.Image, dy int) {
+	drawText(screen, "LESSON: "+lessons[g.lessonIdx].name, 5, dy+12, color.White)
+	status, msg := "Green: best moves", g.currentDialog
+	switch {
+	case g.lessonDone && g.isInCheck(Black):
+		status, msg = "CHECKMATE! WELL DONE.", "Click: next lesson"
+	case g.lessonDone:
+		status, msg = "STALEMATE: NO MOVES,", "NO CHECK = DRAW. CLICK"
+	case g.typingMove:
+		msg = "MOVE: " + g.moveEntry + "_"
+	}
+	drawText(screen, status, 5, dy+24, color.RGBA{255, 215, 0, 255})
+	drawText(screen, msg, 5, dy+36, color.White)
+}
+
+// drawLessonHint marks square x, y for a mating lesson. With nothing
+// selected, the pieces with a best move are outlined in green; with a piece
+// selected, its best destinations are tinted green and any that would
+// stalemate red.
+func (g *Game) drawLessonHint(screen *ebiten.Image, x, y int, px, py float32) {
+	sq, from := Pos{x, y}, Pos{g.selectedX, g.selectedY}
+	if g.selectedX < 0 {
+		for _, m := range g.lessonGood {
+			if m.From == sq {
+				vector.StrokeRect(screen, px+0.5, py+0.5, tileSize-1, tileSize-1, 1, color.NRGBA{0, 255, 100, 200}, false)
+				return
+			}
+		}
 		return
 	}
+	for _, m := range g.lessonGood {
+		if m.From == from && m.To == sq {
+			vector.FillRect(screen, px, py, tileSize, tileSize, color.NRGBA{0, 255, 100, 90}, false)
+		}
+	}
+	for _, m := range g.lessonStalemates {
+		if m.From == from && m.To == sq {
+			vector.FillRect(screen, px, py, tileSize, tileSize, color.NRGBA{255, 0, 0, 110}, false)
+		}
+	}
+}
+
+// drawEditor draws the piece palette in the HUD and the editor's prompt, or
+// its last complaint, across the top border.
+func (g *Game) drawEditor(screen *ebiten.Image) {
+	drawText(screen, g.currentDialog, 2, 12, color.RGBA{255, 215, 0, 255})
+	for i := 0; i < 12; i++ {
+		x, y := paletteX+i%6*tileSize, paletteY+i/6*(tileSize+2)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x), float64(y))
+		screen.DrawImage(sprites[i], op)
+		if i == g.editSprite {
+			vector.StrokeRect(screen, float32(x), float32(y), tileSize, tileSize, 1, color.RGBA{255, 50, 50, 255}, false)
+		}
+	}
+	drawText(screen, "X", sideX+5, paletteY+12, color.White)
+	if g.editSprite < 0 {
+		vector.StrokeRect(screen, sideX, paletteY, tileSize, tileSize, 1, color.RGBA{255, 50, 50, 255}, false)
+	}
+	side := "W MOVES"
+	if g.activeColor == Black {
+		side = "B MOVES"
+	}
+	drawText(screen, side, sideX, paletteY+tileSize+14, color.White)
+}
+
+// drawHistory overlays the most recent full moves on the board, each half-move
+// with the time its side spent on it.
+func (g *Game) drawHistory(screen *ebiten.Image) {
+	const rows = 10
+	vector.FillRect(screen, 0, 0, 160, 160, color.RGBA{0, 0, 0, 220}, false)
+	drawText(screen, "MOVES (H)", 2, 13, color.RGBA{255, 215, 0, 255})
+	first := 0
+	if n := (len(g.history) + 1) / 2; n > rows {
+		first = n - rows
+	}
+	for i := first * 2; i < len(g.history); i++ {
+		y := 30 + (i/2-first)*13
+		m := g.history[i]
+		entry := m.SAN + " " + moveTime(m.Elapsed)
+		if i%2 == 0 {
+			drawText(screen, fmt.Sprintf("%d", i/2+1), 2, y, color.RGBA{150, 150, 150, 255})
+			drawText(screen, entry, 18, y, color.White)
+		} else {
+			drawText(screen, entry, 90, y, color.White)
+		}
+	}
+}
+
+// blindfolded reports whether the pieces are hidden. The board is labelled
+// instead, for moves typed or clicked from memory, and the game ending
+// reveals where everything stood.
+func (g *Game) blindfolded() bool {
+	return g.blindfold && !g.gameOver
+}
+
+// High-contrast highlights are drawn as thick outlines in colors from the
+// Okabe-Ito palette, which stay apart under the common kinds of color
+// blindness, instead of as tints of the square.
+var (
+	contrastSelected = color.RGBA{230, 159, 0, 255}   // orange
+	contrastPremove  = color.RGBA{86, 180, 233, 255}  // sky blue
+	contrastTarget   = color.RGBA{255, 255, 255, 255} // the move awaiting confirmation
+	contrastThreat   = color.RGBA{204, 121, 167, 0}   // reddish purple; alpha set per square
+)
+
+// cueColors are the board-frame flashes for each cue, for players who can't
+// rely on the sound.
+var cueColors = [...]color.RGBA{
+	cueMove:    {200, 200, 200, 255},
+	cueCapture: {255, 140, 0, 255},
+	cueCheck:   {255, 50, 50, 255},
+	cueLowTime: {255, 215, 0, 255},
+}
+
+// outlineSquare draws a high-contrast highlight just inside a square.
+func outlineSquare(screen *ebiten.Image, px, py float64, clr color.Color) {
+	vector.StrokeRect(screen, float32(px)+1, float32(py)+1, tileSize-2, tileSize-2, 2, clr, false)
+}
+
+func (g *Game) drawBoard(screen *ebiten.Image) {
+	contrast := profile != nil && profile.Settings.HighContrast
+	var hanging []Pos
+	if profile != nil && profile.Settings.HangingPieces && g.assists() && !g.editing && !g.demo && !g.gameOver && (g.activeColor == White || g.hotseat) {
+		hanging = g.hanging(g.activeColor)
+	}
+	dests := g.markedDests()
 	for y := 0; y < gridSize; y++ {
 		for x := 0; x < gridSize; x++ {
 			px, py := float64(x*tileSize), float64(y*tileSize)
@@ -472,60 +1549,183 @@ func (g *Game) Draw(screen *ebiten.Image) {
 				op.GeoM.Translate(px, py)
 				screen.DrawImage(sprites[14], op)
 			} else {
-				bx, by := x-1, y-1
+				bx, by, _ := g.boardSquare(int(px), int(py))
+				if !g.onBoard(bx, by) { // the ring around a Los Alamos board
+					op := &ebiten.DrawImageOptions{}
+					op.GeoM.Translate(px, py)
+					screen.DrawImage(sprites[14], op)
+					continue
+				}
 				tID := 13
 				if (bx+by)%2 != 0 {
 					tID = 12
 				}
 				op := &ebiten.DrawImageOptions{}
 				op.GeoM.Translate(px, py)
-				if bx == g.selectedX && by == g.selectedY {
+				selected := bx == g.selectedX && by == g.selectedY
+				premove := Pos{bx, by} == g.premoveFrom || Pos{bx, by} == g.premoveTo
+				if selected && !contrast {
 					op.ColorScale.Scale(2, 0.5, 0.5, 1)
 				}
+				if premove && !contrast {
+					op.ColorScale.Scale(0.5, 0.8, 2, 1)
+				}
 				screen.DrawImage(sprites[tID], op)
+				if g.showThreats && g.assists() && g.activeColor == White && !g.gameOver {
+					if n := len(g.attackersOf(bx, by, Black)); n > 0 {
+						tint := color.NRGBA{255, 0, 0, uint8(min(n, 4) * 40)}
+						if contrast {
+							tint = color.NRGBA{contrastThreat.R, contrastThreat.G, contrastThreat.B, uint8(min(n, 4) * 55)}
+						}
+						vector.FillRect(screen, float32(px), float32(py), tileSize, tileSize, tint, false)
+					}
+				}
+				sprite := -1
 				if p := g.board[by][bx]; p != nil {
+					sprite = p.SpriteID
+				}
+				if g.slide.frames > 0 && g.slide.to == (Pos{bx, by}) {
+					sprite = g.slide.captured
+				}
+				if g.draggedAway(Pos{bx, by}) {
+					sprite = -1
+				}
+				marked := slices.Contains(dests, Pos{bx, by})
+				if marked {
+					drawMoveMark(screen, float32(px), float32(py), sprite >= 0, true)
+				}
+				if sprite >= 0 && !g.blindfolded() {
 					pop := &ebiten.DrawImageOptions{}
 					pop.GeoM.Translate(px, py)
-					screen.DrawImage(sprites[p.SpriteID], pop)
+					screen.DrawImage(sprites[sprite], pop)
+				}
+				if marked {
+					drawMoveMark(screen, float32(px), float32(py), sprite >= 0, false)
+				}
+				if bx == g.pendingX && by == g.pendingY && g.selectedX >= 0 && !g.blindfolded() {
+					gop := &ebiten.DrawImageOptions{}
+					gop.GeoM.Translate(px, py)
+					gop.ColorScale.ScaleAlpha(0.5)
+					screen.DrawImage(sprites[g.board[g.selectedY][g.selectedX].SpriteID], gop)
+				}
+				if f := g.epFade; f.frames > 0 && f.x == bx && f.y == by {
+					a := float32(f.frames) / fadeFrames
+					vector.FillRect(screen, float32(px), float32(py), tileSize, tileSize, color.NRGBA{255, 200, 0, uint8(120 * a)}, false)
+					if !g.blindfolded() {
+						fop := &ebiten.DrawImageOptions{}
+						fop.GeoM.Translate(px, py)
+						fop.ColorScale.ScaleAlpha(a)
+						screen.DrawImage(sprites[f.sprite], fop)
+					}
+				}
+				if slices.Contains(hanging, Pos{bx, by}) && !g.blindfolded() {
+					vector.StrokeRect(screen, float32(px)+0.5, float32(py)+0.5, tileSize-1, tileSize-1, 1, color.NRGBA{255, 120, 0, 160}, false)
+				}
+				if g.lesson && !g.lessonDone && g.activeColor == White && !g.blindfolded() {
+					g.drawLessonHint(screen, bx, by, float32(px), float32(py))
+				}
+				if contrast {
+					switch {
+					case selected:
+						outlineSquare(screen, px, py, contrastSelected)
+					case premove:
+						outlineSquare(screen, px, py, contrastPremove)
+					case bx == g.pendingX && by == g.pendingY && g.selectedX >= 0:
+						outlineSquare(screen, px, py, contrastTarget)
+					}
 				}
 			}
 		}
 	}
-	dy := float32(gridSize * tileSize)
-	vector.FillRect(screen, 0, dy, 160, 40, color.RGBA{10, 10, 15, 255}, false)
-	text.Draw(screen, fmt.Sprintf("W:%02d:%02d B:%02d:%02d", int(g.whiteTime/3600), int(g.whiteTime/60)%60, int(g.blackTime/3600), int(g.blackTime/60)%60), basicfont.Face7x13, 5, int(dy)+12, color.White)
-	text.Draw(screen, fmt.Sprintf("STAKES:$%d WALLET:$%d", g.wager, wallet), basicfont.Face7x13, 5, int(dy)+24, color.RGBA{255, 215, 0, 255})
-	msg := g.hustlerName + ": " + g.currentDialog
-	if g.activeColor == Black && !g.gameOver {
-		msg = g.hustlerName + ": ..."
-	}
-	text.Draw(screen, msg, basicfont.Face7x13, 5, int(dy)+36, color.White)
-	if g.promoting {
-		vector.FillRect(screen, 10, 40, 140, 80, color.RGBA{0, 0, 0, 230}, false)
-		text.Draw(screen, "PROMOTE: Q R B N", basicfont.Face7x13, 25, 80, color.White)
+	if g.blindfolded() {
+		g.drawCoordinates(screen)
+		return
 	}
-	if g.gameOver {
-		vector.FillRect(screen, 0, 50, 160, 60, color.RGBA{0, 0, 0, 240}, false)
-		win := "FRANK WINS"
-		if g.winner == 1 {
-			win = "YOU WIN!"
-		}
-		text.Draw(screen, "CHECKMATE!", basicfont.Face7x13, 45, 75, color.RGBA{255, 50, 50, 255})
-		text.Draw(screen, win, basicfont.Face7x13, 45, 95, color.White)
+	if m := g.slide; m.frames > 0 {
+		t := 1 - float64(m.frames)/slideFrames
+		fx, fy := g.renderSquare(m.from.X, m.from.Y)
+		tx, ty := g.renderSquare(m.to.X, m.to.Y)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(fx)+float64(tx-fx)*t, float64(fy)+float64(ty-fy)*t)
+		screen.DrawImage(sprites[m.sprite], op)
 	}
+	g.drawDrag(screen)
 }
 
-func (g *Game) Layout(w, h int) (int, int) { return 160, 200 }
+// Layout works in real device pixels so the canvas can be scaled up by hand.
+func (g *Game) Layout(w, h int) (int, int) {
+	f := ebiten.Monitor().DeviceScaleFactor()
+	w, h = int(float64(w)*f), int(float64(h)*f)
+	view.scale = max(1, min(w/screenW, h/screenH))
+	view.x, view.y = (w-screenW*view.scale)/2, (h-screenH*view.scale)/2
+	return w, h
+}
 
 func main() {
-	img, _, _ := image.Decode(bytes.NewReader(chessData))
-	sheet := ebiten.NewImageFromImage(img)
-	for y := 0; y < 4; y++ {
-		for x := 0; x < 6; x++ {
-			r := image.Rect(x*tileSize, y*tileSize, (x+1)*tileSize, (y+1)*tileSize)
-			sprites = append(sprites, sheet.SubImage(r).(*ebiten.Image))
+	selfplay := flag.Bool("selfplay", false, "play Frank against himself without a window and print the result")
+	maxMoves := flag.Int("maxmoves", 300, "half-moves before a self-play game is called a draw")
+	games := flag.Int("games", 1, "with -selfplay, play this many seeded games and print a summary")
+	uci := flag.Bool("uci", false, "speak UCI on stdin and stdout so a chess GUI can play Frank")
+	jsonAPI := flag.Bool("json", false, "take JSON commands on stdin and answer with the board state on stdout")
+	flag.BoolVar(&instantFrank, "instant", false, "have Frank move without his think-time delay")
+	flag.IntVar(&thinkPace, "pace", 100, "scale Frank's think time by this percentage")
+	flag.BoolVar(&verboseLog, "verbose", false, "log raw coordinates, the FEN and check state after every move, and print finished games as PGN")
+	fen := flag.String("fen", "", "skip the menu and play an unstaked 5-minute game from this position")
+	puzzleFile := flag.String("puzzles", "", "play the puzzles in this Lichess puzzle CSV instead of the built-in ones")
+	rating := flag.String("rating", "", "with -puzzles, keep puzzles rated LOW-HIGH, like 1200-1600")
+	theme := flag.String("theme", "", "with -puzzles, keep puzzles with this Lichess theme, like fork")
+	pieces := flag.String("pieces", "", "draw the pieces from this PNG, laid out like chess.png, and keep it in the profile")
+	flag.Parse()
+	if *selfplay && *games > 1 {
+		os.Exit(selfPlayMatch(*games, *maxMoves, os.Stdout))
+	}
+	if *selfplay {
+		os.Exit(selfPlay(*maxMoves))
+	}
+	if *uci {
+		os.Exit(uciLoop(os.Stdin, os.Stdout))
+	}
+	if *jsonAPI {
+		os.Exit(jsonLoop(os.Stdin, os.Stdout))
+	}
+
+	if *puzzleFile != "" {
+		lo, hi, err := parseRatingRange(*rating)
+		if err == nil {
+			puzzles, err = loadLichessPuzzles(*puzzleFile, lo, hi, *theme)
+		}
+		if err == nil && len(puzzles) == 0 {
+			err = errors.New("no puzzles match")
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "-puzzles:", err)
+			os.Exit(2)
+		}
+	}
+
+	profile = lastProfile()
+	if *pieces != "" {
+		if abs, err := filepath.Abs(*pieces); err == nil {
+			profile.Settings.PieceSet = abs
+			profile.save()
+		}
+	}
+	useSpriteSheet(profile.Settings.PieceSet)
+	game := &Game{gameStarted: false}
+	if !profile.Tutored {
+		game.tutorial = &tutorial{}
+	}
+	if *fen != "" {
+		game = newPositionGame()
+		if err := game.LoadFEN(*fen); err != nil {
+			fmt.Fprintln(os.Stderr, "-fen:", err)
+			os.Exit(2)
 		}
 	}
+	initAudio()
+	moveSound = playMoveSound
 	ebiten.SetWindowSize(640, 800)
-	ebiten.RunGame(&Game{gameStarted: false})
+	ebiten.SetWindowClosingHandled(true)
+	ebiten.SetTPS(ticksPerSecond)
+	ebiten.RunGame(game)
 }
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain gives the tests a profile of their own that is never saved, as
+// main would load one. The games the tests play are unstaked, so nothing
+// they book touches the disk.
+func TestMain(m *testing.M) {
+	profile = newProfile("TEST")
+	os.Exit(m.Run())
+}
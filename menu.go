@@ -0,0 +1,159 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// menuScreen is a screen of selectable rows. The arrow keys move a highlight
+// over its rows and Enter chooses one; taps choose a row directly.
+type menuScreen int
+
+const (
+	noMenu menuScreen = iota
+	stakesMenu
+	settingsMenu
+	gameOverMenu
+)
+
+// Baselines of the game-over overlay's rows.
+const (
+	againBaseline   = 115
+	quitBaseline    = 128
+	analyzeBaseline = 141
+)
+
+// settingsBackBaseline is the settings screen's way back to the stakes menu,
+// with its page turn on the right half of the row.
+const settingsBackBaseline = 186
+
+// menuRightX is where a row's right-hand item starts. A row can hold two
+// items, split here; a left item alone takes the whole row.
+const menuRightX = 90
+
+// menuItem is one item of a menuScreen: its text baseline and column, which
+// place both the highlight and the item's tap target, and what choosing it
+// does.
+type menuItem struct {
+	baseline int
+	right    bool
+	choose   func(g *Game)
+}
+
+// currentMenu is the menu on screen, or noMenu during play.
+func (g *Game) currentMenu() menuScreen {
+	switch {
+	case !g.gameStarted && (g.reviewBoard != nil || g.tutorial != nil):
+		return noMenu
+	case !g.gameStarted && g.showSettings:
+		return settingsMenu
+	case !g.gameStarted && !g.enteringName:
+		return stakesMenu
+	case g.gameOver && g.puzzle == nil && g.postMortem == nil:
+		return gameOverMenu
+	}
+	return noMenu
+}
+
+// menuItems lists the rows of menu s, top to bottom.
+func menuItems(s menuScreen) []menuItem {
+	var items []menuItem
+	switch s {
+	case stakesMenu:
+		items = append(items,
+			menuItem{stakesHeaderBaseline, false, func(*Game) { rated = !rated }},
+			menuItem{stakesHeaderBaseline, true, func(*Game) { berserk = !berserk }},
+		)
+		for _, o := range menuOptions {
+			items = append(items, menuItem{o.baseline, false, func(g *Game) { *g = *startGame(o.wager, o.mins) }})
+		}
+		items = append(items,
+			menuItem{variantBaseline, false, func(*Game) { variantIdx = (variantIdx + 1) % len(variants) }},
+			menuItem{variantBaseline, true, func(*Game) { oddsIdx = (oddsIdx + 1) % len(materialOdds) }},
+			menuItem{puzzleBaseline, false, func(g *Game) { *g = *NewPuzzleGame(0) }},
+			menuItem{puzzleBaseline, true, func(g *Game) { *g = *NewEditorGame() }},
+			menuItem{lessonBaseline, false, func(g *Game) { *g = *NewLessonGame(0) }},
+			menuItem{styleBaseline, false, func(*Game) { opponentIdx = (opponentIdx + 1) % len(opponents) }},
+			menuItem{settingsBaseline, false, func(g *Game) { g.showSettings = true }},
+			menuItem{settingsBaseline, true, func(g *Game) { *g = *newHotseatGame() }},
+			menuItem{profileBaseline, false, func(*Game) { profile = nextProfile(profile.Name) }},
+		)
+	case settingsMenu:
+		lo, hi := settingsOnPage()
+		for i := lo; i < hi; i++ {
+			items = append(items, menuItem{settingsRowBaseline(i), false, func(*Game) {
+				settingsRows[i].change()
+				profile.save()
+			}})
+		}
+		items = append(items, menuItem{settingsBackBaseline, false, func(g *Game) { g.showSettings = false }})
+	case gameOverMenu:
+		items = []menuItem{
+			{againBaseline, false, func(g *Game) {
+				if g.hotseat {
+					*g = *newHotseatGame()
+				} else {
+					*g = *startGame(g.wager, g.initialMins)
+				}
+			}},
+			{quitBaseline, false, func(g *Game) { *g = Game{gameStarted: false} }},
+			{analyzeBaseline, false, func(g *Game) { g.openPostMortem() }},
+		}
+	}
+	return items
+}
+
+// menuItemAt returns the index of the item under a canvas position, or -1.
+// Items are listed left before right, so on a split row the right item wins
+// past menuRightX.
+func menuItemAt(items []menuItem, x, y int) int {
+	hit := -1
+	for i, it := range items {
+		if onMenuRow(x, y, it.baseline) && (!it.right || x >= menuRightX) {
+			hit = i
+		}
+	}
+	return hit
+}
+
+// navigateMenu runs the keyboard side of the menu on screen and reports
+// whether a row was chosen. The highlight goes back to the top row whenever
+// a different menu comes up.
+func (g *Game) navigateMenu() bool {
+	s := g.currentMenu()
+	if s != g.menuShown {
+		g.menuShown, g.menuCursor = s, 0
+	}
+	items := menuItems(s)
+	if len(items) == 0 {
+		return false
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		g.menuCursor = (g.menuCursor + len(items) - 1) % len(items)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		g.menuCursor = (g.menuCursor + 1) % len(items)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		items[g.menuCursor].choose(g)
+		return true
+	}
+	return false
+}
+
+// drawMenuCursor marks the highlighted item of the menu on screen, at x for
+// a left item and just before menuRightX for a right one.
+func (g *Game) drawMenuCursor(screen *ebiten.Image, x int) {
+	s := g.currentMenu()
+	if s != g.menuShown {
+		return
+	}
+	if items := menuItems(s); g.menuCursor < len(items) {
+		it := items[g.menuCursor]
+		if it.right {
+			x = menuRightX - 8
+		}
+		drawText(screen, ">", x, it.baseline, color.RGBA{255, 215, 0, 255})
+	}
+}
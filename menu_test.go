@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestMenuItemAt(t *testing.T) {
+	items := []menuItem{
+		{58, false, nil},  // a whole row
+		{103, false, nil}, // a split row's left item...
+		{103, true, nil},  // ...and its right one
+	}
+	tests := []struct {
+		name string
+		x, y int
+		want int
+	}{
+		{"whole row, left", 20, 55, 0},
+		{"whole row, right", 120, 55, 0},
+		{"split row, left", 20, 100, 1},
+		{"split row, last pixel before the split", menuRightX - 1, 100, 1},
+		{"split row, right", menuRightX, 100, 2},
+		{"split row, far right", 149, 100, 2},
+		{"between rows", 20, 80, -1},
+		{"left of the box", 5, 100, -1},
+		{"right of the box", 150, 100, -1},
+	}
+	for _, tt := range tests {
+		if got := menuItemAt(items, tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: menuItemAt(%d, %d) = %d, want %d", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
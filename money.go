@@ -0,0 +1,31 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// With the HideMoney setting on, for players streaming their games, nothing
+// about money is drawn: amounts are cut from labels and any line of dialogue
+// that talks money is replaced. The wallet itself is untouched.
+
+var dollarAmount = regexp.MustCompile(`\s*\$\d+`)
+
+// moneyWords mark a line of dialogue as talking money.
+var moneyWords = []string{"$", "money", "cash", "pay"}
+
+// withoutAmounts cuts every dollar amount out of s.
+func withoutAmounts(s string) string {
+	return strings.TrimSpace(dollarAmount.ReplaceAllString(s, ""))
+}
+
+// talksMoney reports whether a line of dialogue mentions money.
+func talksMoney(s string) bool {
+	s = strings.ToLower(s)
+	for _, w := range moneyWords {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// How the squares a selected or dragged piece can move to are marked, by
+// the Legal moves setting: a tint over the whole square, a dot on an empty
+// square and a ring around a piece it would take, or nothing.
+const (
+	marksTint = iota
+	marksDots
+	marksOff
+)
+
+// moveMarkNames are the Legal moves setting's values, indexed as above.
+var moveMarkNames = []string{"TINT", "DOTS", "OFF"}
+
+// markedDests lists the squares to mark as the selected piece's legal
+// moves. The lesson and the editor mark squares their own way, and
+// blindfold marks none.
+func (g *Game) markedDests() []Pos {
+	if profile == nil || profile.Settings.MoveMarks == marksOff || g.selectedX < 0 || g.lesson || g.editing || g.blindfolded() {
+		return nil
+	}
+	var dests []Pos
+	for _, m := range g.LegalMoves(g.activeColor) {
+		if m.From.X == g.selectedX && m.From.Y == g.selectedY {
+			dests = append(dests, m.To)
+		}
+	}
+	return dests
+}
+
+// drawMoveMark marks the square at px, py as a legal move, before its piece
+// is drawn when under says so and after it otherwise, so a tint sits under
+// the piece and a dot or ring over the square.
+func drawMoveMark(screen *ebiten.Image, px, py float32, capture, under bool) {
+	style := profile.Settings.MoveMarks
+	if under != (style == marksTint) {
+		return
+	}
+	cx, cy := px+tileSize/2, py+tileSize/2
+	switch {
+	case style == marksTint:
+		vector.FillRect(screen, px, py, tileSize, tileSize, color.NRGBA{0, 255, 100, 60}, false)
+	case capture:
+		vector.StrokeCircle(screen, cx, cy, tileSize/2-1, 1.5, color.NRGBA{0, 200, 80, 200}, true)
+	default:
+		vector.FillCircle(screen, cx, cy, 2.5, color.NRGBA{0, 200, 80, 200}, true)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MoveRecord is one half-move of the game history.
+type MoveRecord struct {
+	SAN     string
+	Elapsed float64 // seconds the mover's clock ran on this move
+}
+
+// SAN piece letters, indexed by PieceType.
+var pieceLetters = []string{"", "B", "R", "N", "Q", "K"}
+
+// san spells m in standard algebraic notation, minus any promotion or check
+// suffix. Call it before the move is made. When another piece of the same
+// kind could also legally go to m.To, the mover's file, rank or whole square
+// is added, whichever tells them apart first: Nbd2, R1e2, Qh4e1.
+func (g *Game) san(m Move) string {
+	fx, fy, tx, ty := m.From.X, m.From.Y, m.To.X, m.To.Y
+	p := g.board[fy][fx]
+	if rx := g.castleRookFile(p, m); rx >= 0 {
+		if rx > fx {
+			return "O-O"
+		}
+		return "O-O-O"
+	}
+	s := pieceLetters[p.Type] + g.disambiguation(p, m)
+	if g.board[ty][tx] != nil || (p.Type == Pawn && tx == g.epX && ty == g.epY) {
+		if p.Type == Pawn {
+			s = toAlg(fx, fy)[:1]
+		}
+		s += "x"
+	}
+	return s + toAlg(tx, ty)
+}
+
+// disambiguation is what SAN puts between a piece's letter and the rest of
+// m so that no other piece of the same kind and color could be read as the
+// mover. Pawns are told apart by the file a capture leaves from, and there
+// is only one king, so they never need it.
+func (g *Game) disambiguation(p *ChessPiece, m Move) string {
+	if p.Type == Pawn || p.Type == King {
+		return ""
+	}
+	rivals, sameFile, sameRank := 0, false, false
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			q := g.board[y][x]
+			if q == nil || q == p || q.Type != p.Type || q.Color != p.Color {
+				continue
+			}
+			alt := Move{From: Pos{x, y}, To: m.To}
+			if !g.isMoveLegal(q, alt) || !g.isMoveSafe(alt) {
+				continue
+			}
+			rivals++
+			sameFile = sameFile || x == m.From.X
+			sameRank = sameRank || y == m.From.Y
+		}
+	}
+	sq := toAlg(m.From.X, m.From.Y)
+	switch {
+	case rivals == 0:
+		return ""
+	case !sameFile:
+		return sq[:1]
+	case !sameRank:
+		return sq[1:]
+	}
+	return sq
+}
+
+// checkSuffix is "+" or "#" when the side to move is in check.
+func (g *Game) checkSuffix() string {
+	if !g.isInCheck(g.activeColor) {
+		return ""
+	}
+	if g.hasLegalMoves(g.activeColor) {
+		return "+"
+	}
+	return "#"
+}
+
+// moveTime formats a move's elapsed seconds compactly for the history panel.
+func moveTime(secs float64) string {
+	if secs < 10 {
+		return fmt.Sprintf("%.1fs", secs)
+	}
+	return fmt.Sprintf("%.0fs", secs)
+}
+
+// parseUCI reads a long-algebraic move such as "e2e4" or "e7e8q". Promo is
+// Queen when no promotion piece is given.
+func parseUCI(s string) (Move, bool) {
+	if len(s) != 4 && len(s) != 5 {
+		return Move{}, false
+	}
+	for _, i := range []int{0, 2} {
+		if s[i] < 'a' || s[i] > 'h' || s[i+1] < '1' || s[i+1] > '8' {
+			return Move{}, false
+		}
+	}
+	m := Move{From: Pos{int(s[0] - 'a'), 8 - int(s[1]-'0')}, To: Pos{int(s[2] - 'a'), 8 - int(s[3]-'0')}, Promo: Queen}
+	if len(s) == 5 {
+		t := strings.IndexByte(fenLetters, s[4])
+		if t < int(Bishop) || t > int(Queen) {
+			return Move{}, false
+		}
+		m.Promo = PieceType(t)
+	}
+	return m, true
+}
+
+// lastUCI is the most recent move in long algebraic notation.
+func (g *Game) lastUCI() string {
+	s := toAlg(g.lastFrom.X, g.lastFrom.Y) + toAlg(g.lastTo.X, g.lastTo.Y)
+	if n := len(g.history); n > 0 {
+		if i := strings.IndexByte(g.history[n-1].SAN, '='); i >= 0 {
+			s += strings.ToLower(g.history[n-1].SAN[i+1 : i+2])
+		}
+	}
+	return s
+}
+
+// lineText spells a line of moves played from the current position, such
+// as a search's principal variation, in SAN ("e4 e5 Nf3") or, for UCI, long
+// algebraic ("e2e4 e7e5 g1f3").
+func (g *Game) lineText(line []Move, uci bool) string {
+	c := g.clone()
+	words := make([]string, 0, len(line))
+	for _, m := range line {
+		c.executeMove(m)
+		if uci {
+			words = append(words, c.lastUCI())
+		} else {
+			words = append(words, c.history[len(c.history)-1].SAN)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// parseMove reads a typed move for the side to move, in SAN ("Nf3", "exd5",
+// "Rad1", "O-O-O", "e8=Q" or "e8Q") or long algebraic ("e2e4"), and checks it
+// against the legal moves. Promo is Queen unless the move names another piece.
+func (g *Game) parseMove(s string) (Move, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "+#!?")
+	if m, ok := parseUCI(strings.ToLower(s)); ok {
+		if p := g.board[m.From.Y][m.From.X]; p == nil || p.Color != g.activeColor || !g.isMoveLegal(p, m) || !g.isMoveSafe(m) {
+			return Move{}, errors.New("ILLEGAL MOVE")
+		}
+		if m.Promo == Bishop && g.losAlamos {
+			return Move{}, errors.New("NO BISHOPS HERE")
+		}
+		return m, nil
+	}
+
+	s = strings.ReplaceAll(s, "0", "O")
+	promo := Queen
+	if n := len(s); n >= 3 && (s[n-2] == '1' || s[n-2] == '8') && strings.IndexByte("BRNQ", s[n-1]) >= 0 {
+		s = s[:n-1] + "=" + s[n-1:]
+	}
+	if i := strings.IndexByte(s, '='); i >= 0 {
+		t := strings.Index("PBRNQK", s[i+1:])
+		if i+2 != len(s) || t < int(Bishop) || t > int(Queen) || t == int(Bishop) && g.losAlamos {
+			return Move{}, fmt.Errorf("BAD PROMOTION %q", s[i:])
+		}
+		s, promo = s[:i], PieceType(t)
+	}
+	castle := s == "O-O" || s == "O-O-O"
+
+	// Everything before the destination square narrows down the mover: an
+	// optional piece letter, then an optional file and/or rank, then "x".
+	var t PieceType
+	var from string
+	var to Pos
+	if !castle {
+		if len(s) < 2 {
+			return Move{}, errors.New("BAD MOVE")
+		}
+		dest := s[len(s)-2:]
+		if dest[0] < 'a' || dest[0] > 'h' || dest[1] < '1' || dest[1] > '8' {
+			return Move{}, errors.New("BAD MOVE")
+		}
+		to = Pos{int(dest[0] - 'a'), 8 - int(dest[1]-'0')}
+		from = strings.TrimSuffix(s[:len(s)-2], "x")
+		if from != "" {
+			if i := strings.Index("PBRNQK", from[:1]); i >= 0 {
+				t, from = PieceType(i), from[1:]
+			}
+		}
+	}
+
+	var found []Move
+	for _, m := range g.LegalMoves(g.activeColor) {
+		p := g.board[m.From.Y][m.From.X]
+		if castle {
+			if g.castleRookFile(p, m) < 0 || g.san(m) != s {
+				continue
+			}
+		} else if m.To != to || p.Type != t || !matchesFrom(m.From.X, m.From.Y, from) {
+			continue
+		}
+		m.Promo = promo
+		found = append(found, m)
+	}
+	switch len(found) {
+	case 0:
+		return Move{}, errors.New("ILLEGAL MOVE")
+	case 1:
+		return found[0], nil
+	}
+	return Move{}, errors.New("AMBIGUOUS MOVE")
+}
+
+// matchesFrom reports whether the square x, y fits a SAN disambiguation: a
+// file, a rank, a full square, or nothing at all.
+func matchesFrom(x, y int, from string) bool {
+	sq := toAlg(x, y)
+	for _, r := range from {
+		if !strings.ContainsRune(sq, r) {
+			return false
+		}
+	}
+	return len(from) <= 2
+}
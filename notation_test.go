@@ -0,0 +1,139 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSANDisambiguation(t *testing.T) {
+	tests := []struct {
+		name, fen, move, want string
+	}{
+		{"file", "4k3/8/8/8/8/5N2/8/1N2K3 w - - 0 1", "b1d2", "Nbd2"},
+		{"file, other knight", "4k3/8/8/8/8/5N2/8/1N2K3 w - - 0 1", "f3d2", "Nfd2"},
+		{"rank", "k7/8/8/8/8/4R3/8/4R1K1 w - - 0 1", "e1e2", "R1e2"},
+		{"rank, black", "r3k3/8/8/8/8/8/7K/r7 b - - 0 1", "a8a4", "R8a4"},
+		{"full square", "2k5/8/8/8/4Q2Q/8/8/K6Q w - - 0 1", "h4e1", "Qh4e1"},
+		{"file among three", "2k5/8/8/8/4Q2Q/8/8/K6Q w - - 0 1", "e4e1", "Qee1"},
+		{"rank among three", "2k5/8/8/8/4Q2Q/8/8/K6Q w - - 0 1", "h1e1", "Q1e1"},
+		{"pinned twin", "4r1k1/8/8/8/8/8/4N3/2N1K3 w - - 0 1", "c1d3", "Nd3"},
+		{"blocked twin", "4k3/8/8/8/8/8/8/R3K2R w - - 0 1", "a1d1", "Rd1"},
+		{"pawn capture", "4k3/8/8/3p1p2/4P3/8/8/4K3 b - - 0 1", "d5e4", "dxe4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(0, 0)
+			if err := g.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			m, err := g.parseMove(tt.move)
+			if err != nil {
+				t.Fatalf("parseMove(%q): %v", tt.move, err)
+			}
+			got := g.san(m)
+			if got != tt.want {
+				t.Fatalf("san(%s) = %q, want %q", tt.move, got, tt.want)
+			}
+			back, err := g.parseMove(got)
+			if err != nil {
+				t.Fatalf("parseMove(%q): %v", got, err)
+			}
+			if back.From != m.From || back.To != m.To {
+				t.Errorf("parseMove(%q) = %v, want %v", got, back, m)
+			}
+		})
+	}
+}
+
+func TestMoveHistoryTimes(t *testing.T) {
+	g := NewGame(0, 5)
+	g.whiteTime -= 2.1 * ticksPerSecond
+	playMoves(t, g, "Nf3")
+	g.blackTime -= 15 * ticksPerSecond
+	playMoves(t, g, "Nf6")
+	for i, want := range []string{"Nf3 2.1s", "Nf6 15s"} {
+		m := g.history[i]
+		if got := m.SAN + " " + moveTime(m.Elapsed); got != want {
+			t.Errorf("history[%d] = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestParseMove(t *testing.T) {
+	tests := []struct {
+		name, fen, move string
+		want            string // from and to in UCI, with a promotion letter; "" for an error
+	}{
+		{"pawn push", standardFEN, "e4", "e2e4"},
+		{"long algebraic", standardFEN, "g1f3", "g1f3"},
+		{"check mark ignored", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "Ra8+", "a1a8q"},
+		{"ambiguous knights", "4k3/8/8/8/8/5N2/8/1N2K3 w - - 0 1", "Nd2", ""},
+		{"ambiguous rooks", "k7/8/8/8/8/4R3/8/4R1K1 w - - 0 1", "Re2", ""},
+		{"lower-case piece letter", standardFEN, "nf3", ""},
+		{"lower-case bishop read as a pawn", "4k3/8/8/8/8/8/1P6/2B1K3 w - - 0 1", "bd2", ""},
+		{"promotion with =", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8=N", "e7e8n"},
+		{"promotion without =", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8R", "e7e8r"},
+		{"capture promotion without =", "3r4/4P3/8/8/8/8/k7/4K3 w - - 0 1", "exd8N", "e7d8n"},
+		{"promotion defaults to queen", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8", "e7e8q"},
+		{"promotion to king", "8/4P3/8/8/8/8/k7/4K3 w - - 0 1", "e8=K", ""},
+		{"castling", "4k3/8/8/8/8/8/8/4K2R w K - 0 1", "O-O", "e1g1"},
+		{"castling with zeros", "r3k3/8/8/8/8/8/8/4K3 b q - 0 1", "0-0-0", "e8c8"},
+		{"castling without the right", "4k3/8/8/8/8/8/8/4K2R w - - 0 1", "O-O", ""},
+		{"illegal SAN", standardFEN, "e5", ""},
+		{"illegal long algebraic", standardFEN, "e2e5", ""},
+		{"opponent's piece", standardFEN, "e7e5", ""},
+		{"into check", "4k3/8/8/8/8/8/4r3/4K3 w - - 0 1", "Kd2", ""},
+		{"garbage", standardFEN, "hello", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(0, 0)
+			if err := g.LoadFEN(tt.fen); err != nil {
+				t.Fatal(err)
+			}
+			m, err := g.parseMove(tt.move)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("parseMove(%q) = %v, want an error", tt.move, m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMove(%q): %v", tt.move, err)
+			}
+			got := toAlg(m.From.X, m.From.Y) + toAlg(m.To.X, m.To.Y)
+			if len(tt.want) == 5 {
+				got += string(fenLetters[m.Promo])
+			}
+			if got != tt.want {
+				t.Errorf("parseMove(%q) = %s, want %s", tt.move, got, tt.want)
+			}
+		})
+	}
+}
+
+// In Chess960 the king "captures" its own rook to castle, wherever the two
+// start, and O-O and O-O-O name the rook on the h- and a-side.
+func TestParseMoveCastling960(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		g := NewGame(0, 0)
+		g.rng = rand.New(rand.NewSource(seed))
+		g.chess960 = true
+		g.rebuildBoard()
+		for x := 0; x < 8; x++ {
+			if p := g.board[7][x]; p.Type != King && p.Type != Rook {
+				g.board[7][x] = nil
+			}
+		}
+		for side, s := range []string{"O-O-O", "O-O"} {
+			m, err := g.parseMove(s)
+			if err != nil {
+				t.Fatalf("seed %d: parseMove(%q): %v\n%s", seed, s, err, g.FEN())
+			}
+			k := g.board[m.From.Y][m.From.X]
+			if k.Type != King || m.To.Y != 7 || m.To.X != g.rookFiles[side] {
+				t.Errorf("seed %d: %s = %v, want king takes rook on file %d", seed, s, m, g.rookFiles[side])
+			}
+		}
+	}
+}
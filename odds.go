@@ -0,0 +1,53 @@
+package main
+
+// materialOdds are the handicaps a hustler will give: a piece he starts the
+// game without, the queen's-side one where he has two, and the percent of
+// the wager a win then pays. Losing still costs the whole wager.
+var materialOdds = []struct {
+	name   string // on the menu
+	piece  PieceType
+	payout int
+	line   string // the hustler announcing it, with the payout
+}{
+	{"-", Pawn, 100, ""},
+	{"N", Knight, 75, "Knight odds. Wins pay $%d."},
+	{"R", Rook, 50, "Rook odds. Wins pay $%d."},
+	{"Q", Queen, 25, "Queen odds. Wins pay $%d."},
+}
+
+// removeOddsPiece takes the handicap piece off Black's back rank, along with
+// the castling right of a rook.
+func (g *Game) removeOddsPiece() {
+	if g.odds == 0 {
+		return
+	}
+	t := materialOdds[g.odds].piece
+	y := g.lastRank(White)
+	for x := 0; x < 8; x++ {
+		if p := g.board[y][x]; p != nil && p.Type == t {
+			g.board[y][x] = nil
+			if x == g.rookFiles[0] {
+				g.castling[Black][0] = false
+			} else if x == g.rookFiles[1] {
+				g.castling[Black][1] = false
+			}
+			return
+		}
+	}
+}
+
+// berserkBonus is the percent a berserk win adds to the payout, for playing
+// on half the clock.
+const berserkBonus = 50
+
+// payout is what the player collects for winning this game.
+func (g *Game) payout() int {
+	p := g.wager * materialOdds[g.odds].payout / 100
+	if g.berserk {
+		p += p * berserkBonus / 100
+	}
+	if g.armageddon {
+		p += p * armageddonBonus / 100
+	}
+	return p * g.payoutRate / 100
+}
@@ -0,0 +1,77 @@
+package main
+
+// Opponent is one of the hustlers working the park's chess tables: how he
+// plays, how deep he looks and what he has to say about it.
+type Opponent struct {
+	Name     string // as shown beside his dialogue
+	Short    string // as shown when he wins
+	Style    int    // index into personalities
+	Depth    int    // plies searched
+	Greeting string
+	Mates    string // he has just mated the player
+	Mated    string // the player has just mated him
+}
+
+var opponents = []Opponent{
+	{"4-Move-Frank", "FRANK", 0, searchDepth, "Eyes on the board, kid.", "MATE! Give me my money.", "MATE! Take the cash."},
+	{"Gambit-Gabby", "GABBY", 1, searchDepth, "Pawns are for throwing.", "Told you. Attack wins.", "Ugh. Got greedy."},
+	{"Endgame-Ernie", "ERNIE", 3, searchDepth, "Trade 'em off, kid.", "Endgames. My house.", "Hmph. Well played."},
+	{"Rookie-Ray", "RAY", 2, 1, "First time? Me too.", "Whoa, I won?", "Good game, pal."},
+}
+
+// rival is the opponent across the board; games that never seated one, like
+// puzzles and the menu's demo, get Frank.
+func (g *Game) rival() *Opponent {
+	if g.opponent == nil {
+		return &opponents[0]
+	}
+	return g.opponent
+}
+
+// seat puts o across the board, with his name and playing style, shifted
+// for the game's stakes.
+func (g *Game) seat(o *Opponent) {
+	g.opponent = o
+	g.hustlerName = o.Name
+	style, deeper := stakesStyle(personalities[o.Style], g.wager)
+	g.personality, g.stakesDepth = &style, deeper
+}
+
+// Stakes change how a hustler plays. From solidStakes up he can't afford to
+// lose: he counts material for more, keeps his king covered, stops chasing
+// attacks and looks a ply deeper. At gambleStakes and under it's pocket
+// change, so he throws pawns and pieces at the king and plays looser.
+const (
+	gambleStakes = 10
+	solidStakes  = 50
+)
+
+// stakesStyle is s shifted for a game played for wager, and the plies to
+// add to the opponent's depth. Unstaked games get s as it is.
+func stakesStyle(s Personality, wager int) (Personality, int) {
+	switch {
+	case wager >= solidStakes:
+		s.Material += 10
+		s.KingAttack /= 2
+		s.KingShield = s.KingShield*3/2 + 2
+		s.Noise /= 2
+		return s, 1
+	case wager > 0 && wager <= gambleStakes:
+		s.Material -= 10
+		s.KingAttack = s.KingAttack*3/2 + 2
+		s.PawnPush = s.PawnPush*3/2 + 1
+		s.Noise = s.Noise*3/2 + 5
+	}
+	return s, 0
+}
+
+// stakesLine is what a hustler says about the stakes once the clocks start.
+func stakesLine(wager int) string {
+	switch {
+	case wager >= solidStakes:
+		return "Big money. No gambles."
+	case wager > 0 && wager <= gambleStakes:
+		return "Small change? I gamble."
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reasons a game ends, as shown on the game-over overlay.
+const (
+	endCheckmate  = "CHECKMATE"
+	endStalemate  = "STALEMATE"
+	endTime       = "TIME"
+	endDead       = "DEAD POSITION"
+	endFiftyMove  = "DRAW (50-move)"
+	endRepetition = "DRAW (repetition)"
+	endPerpetual  = "DRAW (perpetual)"
+)
+
+// More than one ending can apply at once: a mate can land on the hundredth
+// half-move, or a clock can run out with a threefold repetition on the board.
+// The first of these that applies decides the game:
+//
+//  1. checkmate, stalemate and a dead position, which end the game the
+//     moment the move making them is played (finalOutcome);
+//  2. the side to move running out of time, drawn when the other side could
+//     never mate (flagFall and timeoutIsDraw);
+//  3. the draws by rule, the fifty-move rule and then repetition
+//     (drawByRule).
+//
+// judge checks them in that order every frame. Headless games have no
+// clocks and use outcome, which is steps 1 and 3 together.
+//
+// A move still waiting for its promotion piece isn't finished, so none of
+// these are judged until it is: a capture that promotes and mates, like
+// bxa8=Q#, is a mate only once the queen is on a8. promote then ends the
+// turn, which gives the SAN its "=Q" and then its "#".
+
+// outcome reports whether the position on the board has ended the game, and
+// how. winner follows Game.winner: 1 for White, 0 for Black, -1 for a draw.
+// Running out of time is the clock's business and isn't checked here.
+func (g *Game) outcome() (reason string, winner int, over bool) {
+	if reason, winner, over := g.finalOutcome(); over {
+		return reason, winner, over
+	}
+	return g.drawByRule()
+}
+
+// judge ends the game if one of the endings above applies, checking them in
+// that order with the side to move's clock running down by dt between the
+// board's verdict and the draws by rule, and reports whether it did. An
+// expired side bet is settled once the board has had its say, and a paused
+// game's clock and draws wait. Update calls it every frame.
+func (g *Game) judge(dt float64, paused bool) bool {
+	if reason, winner, over := g.finalOutcome(); over {
+		g.endGame(reason, winner)
+		return true
+	}
+	if g.betExpired() {
+		g.settleBet()
+	}
+	if paused {
+		return false
+	}
+	if g.tickClock(dt) {
+		return true
+	}
+	if reason, winner, over := g.drawByRule(); over {
+		g.endGame(reason, winner)
+		return true
+	}
+	return false
+}
+
+// finalOutcome reports the endings of step 1 above, which outrank the clock.
+func (g *Game) finalOutcome() (reason string, winner int, over bool) {
+	switch {
+	case g.promoting:
+		// The promoting pawn is still a pawn on the last rank, and the
+		// move's side still to move.
+	case !g.hasLegalMoves(g.activeColor):
+		if !g.isInCheck(g.activeColor) {
+			return endStalemate, -1, true
+		}
+		return endCheckmate, int(1 - g.activeColor), true
+	case g.isDeadPosition():
+		return endDead, -1, true
+	}
+	return "", -1, false
+}
+
+// drawByRule reports the endings of step 3 above, which the clock outranks.
+func (g *Game) drawByRule() (reason string, winner int, over bool) {
+	switch {
+	case g.halfmoveClock >= 100:
+		return endFiftyMove, -1, true
+	case g.repetitions[g.positionKey()] >= 3:
+		return endRepetition, -1, true
+	}
+	return "", -1, false
+}
+
+// positionKey identifies a position for repetition: the FEN's placement,
+// side to move, castling and en passant fields.
+func (g *Game) positionKey() string {
+	return strings.Join(strings.Fields(g.FEN())[:4], " ")
+}
+
+// recordPosition counts one more occurrence of the current position and adds
+// it to the game's positions. Search clones have no repetition map and skip
+// it, since building the key for every trial move would slow Frank down.
+func (g *Game) recordPosition() {
+	if g.repetitions != nil {
+		key := g.positionKey()
+		g.repetitions[key]++
+		g.positions = append(g.positions, key)
+	}
+}
+
+// perpetualCheck reports a perpetual check: the position has come round
+// again, and every move the side that just moved made in between gave check.
+// The game would be drawn by repetition soon enough, so either player may
+// settle it as a draw at once, before the third time.
+func (g *Game) perpetualCheck() (checker Color, ok bool) {
+	n := len(g.positions)
+	if n < 2 || g.repetitions[g.positions[n-1]] < 2 {
+		return 0, false
+	}
+	prev := -1
+	for i := n - 3; i >= 0; i -= 2 { // same side to move
+		if g.positions[i] == g.positions[n-1] {
+			prev = i
+			break
+		}
+	}
+	if prev < 0 {
+		return 0, false
+	}
+	// positions[i+1] follows history[i]; the checker made the last move,
+	// and every other one back to the first repetition.
+	for i := n - 2; i >= prev; i -= 2 {
+		san := g.history[i].SAN
+		if !strings.HasSuffix(san, "+") && !strings.HasSuffix(san, "#") {
+			return 0, false
+		}
+	}
+	return 1 - g.activeColor, true
+}
+
+// drawCounters is the 50-move count and, once the position has been seen
+// before, its repetition count, each against the total that ends the game.
+func (g *Game) drawCounters() string {
+	s := fmt.Sprintf("50-MOVE:%d/100", g.halfmoveClock)
+	if n := g.repetitions[g.positionKey()]; n > 1 {
+		s += fmt.Sprintf(" REP:%d/3", n)
+	}
+	return s
+}
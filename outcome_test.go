@@ -0,0 +1,170 @@
+package main
+
+import "testing"
+
+// bxa8=Q# from here takes the rook, promotes and mates at once.
+const promoMateFEN = "r6k/1P4pp/8/8/8/8/8/6K1 w - - 0 1"
+
+func TestPromotionCaptureMate(t *testing.T) {
+	for _, picker := range []bool{true, false} {
+		g := NewGame(0, 5)
+		if err := g.LoadFEN(promoMateFEN); err != nil {
+			t.Fatal(err)
+		}
+		g.autoQueen = !picker
+		m, err := g.parseMove("b7a8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Promo = Pawn // as a click leaves it, with no piece named
+		g.executeMove(m)
+		if g.promoting != picker {
+			t.Fatalf("picker=%v: promoting = %v", picker, g.promoting)
+		}
+		if picker {
+			if reason, _, over := g.outcome(); over {
+				t.Errorf("outcome before the piece is picked = %q, want none", reason)
+			}
+			g.promote(Queen)
+		}
+		if got := g.history[len(g.history)-1].SAN; got != "bxa8=Q#" {
+			t.Errorf("picker=%v: SAN = %q, want %q", picker, got, "bxa8=Q#")
+		}
+		if reason, winner, over := g.outcome(); !over || reason != endCheckmate || winner != int(White) {
+			t.Errorf("picker=%v: outcome = %q, %d, %v, want %q for White", picker, reason, winner, over, endCheckmate)
+		}
+	}
+}
+
+// playMoves plays moves, in any notation parseMove reads, failing the test on
+// one it can't.
+func playMoves(t *testing.T, g *Game, moves ...string) {
+	t.Helper()
+	for _, s := range moves {
+		m, err := g.parseMove(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		g.executeMove(m)
+	}
+}
+
+func TestMateOnHundredthHalfMoveBeatsFiftyMoveRule(t *testing.T) {
+	g := NewGame(0, 5)
+	g.demo = true
+	if err := g.LoadFEN("7k/6pp/8/8/8/8/8/R5K1 w - - 99 80"); err != nil {
+		t.Fatal(err)
+	}
+	playMoves(t, g, "Ra8")
+	if reason, _, over := g.drawByRule(); !over || reason != endFiftyMove {
+		t.Fatalf("drawByRule = %q, %v; the case needs the fifty-move rule to apply too", reason, over)
+	}
+	if !g.judge(0, false) || g.endReason != endCheckmate || g.winner != int(White) {
+		t.Errorf("ended %v with %q, winner %d; want %q for White", g.gameOver, g.endReason, g.winner, endCheckmate)
+	}
+}
+
+func TestFlagFallBeatsRepetition(t *testing.T) {
+	g := NewGame(0, 5)
+	g.demo = true
+	playMoves(t, g, "Nf3", "Nf6", "Ng1", "Ng8", "Nf3", "Nf6", "Ng1", "Ng8")
+	if reason, _, over := g.drawByRule(); !over || reason != endRepetition {
+		t.Fatalf("drawByRule = %q, %v; the case needs a threefold repetition too", reason, over)
+	}
+	g.whiteTime = 1
+	if !g.judge(2, false) || g.endReason != endTime || g.winner != int(Black) {
+		t.Errorf("ended %v with %q, winner %d; want %q for Black", g.gameOver, g.endReason, g.winner, endTime)
+	}
+}
+
+func TestMateBeatsFlag(t *testing.T) {
+	g := NewGame(0, 5)
+	g.demo = true
+	if err := g.LoadFEN("7k/6pp/8/8/8/8/8/R5K1 w - - 0 1"); err != nil {
+		t.Fatal(err)
+	}
+	playMoves(t, g, "Ra8")
+	g.blackTime = 0
+	if !g.judge(2, false) || g.endReason != endCheckmate || g.winner != int(White) {
+		t.Errorf("ended %v with %q, winner %d; want %q for White", g.gameOver, g.endReason, g.winner, endCheckmate)
+	}
+}
+
+func TestFlagFall(t *testing.T) {
+	tests := []struct {
+		name, fen string
+		flagged   Color
+		want      int
+	}{
+		{"bare king flags against king and bishop", "4k3/8/8/8/8/8/8/2B1K3 w - - 0 1", Black, -1},
+		{"king and bishop flag against bare king", "4k3/8/8/8/8/8/8/2B1K3 w - - 0 1", White, -1},
+		{"bare king flags against king and rook", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", Black, int(White)},
+		{"king and rook flag against bare king", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", White, -1},
+		{"king and pawn flag against king and bishop", "4k3/4p3/8/8/8/8/8/2B1K3 w - - 0 1", Black, int(White)},
+	}
+	for _, tt := range tests {
+		g := NewGame(0, 5)
+		g.demo = true
+		if err := g.LoadFEN(tt.fen); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		g.flagFall(tt.flagged)
+		if !g.gameOver || g.endReason != endTime || g.winner != tt.want {
+			t.Errorf("%s: ended %v with %q, winner %d; want %q, winner %d", tt.name, g.gameOver, g.endReason, g.winner, endTime, tt.want)
+		}
+	}
+}
+
+func TestPerpetualCheck(t *testing.T) {
+	const (
+		white = "6k1/6p1/8/8/8/8/8/K3Q3 w - - 0 1" // White's queen checks from e8 and h5
+		black = "k3q3/8/8/8/8/8/6P1/6K1 b - - 0 1" // the same, colors swapped
+	)
+	tests := []struct {
+		name, fen string
+		moves     []string
+		checker   Color
+		want      bool
+	}{
+		{"queen checks until the position repeats", white, []string{"Qe8+", "Kh7", "Qh5+", "Kg8", "Qe8+"}, White, true},
+		{"black queen checks until the position repeats", black, []string{"Qe1+", "Kh2", "Qh4+", "Kg1", "Qe1+"}, Black, true},
+		{"one quiet queen move in the cycle", white, []string{"Qe8+", "Kh7", "Qe5", "Kg8", "Qe8+"}, 0, false},
+		{"the checked king's moves repeat", white, []string{"Qe8+", "Kh7", "Qh5+", "Kg8", "Qe8+", "Kh7"}, 0, false},
+		{"no repetition yet", white, []string{"Qe8+", "Kh7", "Qh5+", "Kg8"}, 0, false},
+	}
+	for _, tt := range tests {
+		g := NewGame(0, 5)
+		if err := g.LoadFEN(tt.fen); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		playMoves(t, g, tt.moves...)
+		checker, ok := g.perpetualCheck()
+		if ok != tt.want || (ok && checker != tt.checker) {
+			t.Errorf("%s: perpetualCheck = %d, %v, want %d, %v", tt.name, checker, ok, tt.checker, tt.want)
+		}
+	}
+}
+
+func TestFrankClaimsPerpetual(t *testing.T) {
+	tests := []struct {
+		name    string
+		eval    int
+		hotseat bool
+		want    bool
+	}{
+		{"Frank worse takes the draw", 150, false, true},
+		{"Frank better plays on", -150, false, false},
+		{"nobody claims for Black in hot seat", 150, true, false},
+	}
+	for _, tt := range tests {
+		g := NewGame(0, 5)
+		if err := g.LoadFEN("k3q3/8/8/8/8/8/6P1/6K1 b - - 0 1"); err != nil {
+			t.Fatal(err)
+		}
+		playMoves(t, g, "Qe1+", "Kh2", "Qh4+", "Kg1", "Qe1+")
+		g.evals, g.hotseat = []int{tt.eval}, tt.hotseat
+		if got := g.frankClaimsPerpetual(); got != tt.want {
+			t.Errorf("%s: frankClaimsPerpetual = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
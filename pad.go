@@ -0,0 +1,102 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// The on-screen pad is for players who can't click a 16-pixel square
+// precisely: big buttons along the bottom of the HUD move a cursor over the
+// board, and OK does what a click on the cursor's square would. The arrow
+// keys and Space work it too. It takes the place of the stakes and dialogue
+// lines while the TouchPad setting is on.
+
+// padButtons are the pad's buttons, left to right: the arrows step the
+// cursor a square across the screen, and OK, with no step, clicks.
+var padButtons = []struct {
+	label  string
+	dx, dy int
+	key    ebiten.Key
+}{
+	{"<", -1, 0, ebiten.KeyLeft},
+	{"^", 0, -1, ebiten.KeyUp},
+	{"v", 0, 1, ebiten.KeyDown},
+	{">", 1, 0, ebiten.KeyRight},
+	{"OK", 0, 0, ebiten.KeySpace},
+}
+
+// Pad button geometry, in canvas pixels.
+const (
+	padY   = gridSize*tileSize + 16
+	padW   = 30
+	padH   = 22
+	padGap = 2
+)
+
+// showsPad reports whether the pad is on screen: the setting is on and the
+// HUD is the game one, not a puzzle's, the lesson's or the editor's.
+func (g *Game) showsPad() bool {
+	return profile.Settings.TouchPad && g.puzzle == nil && !g.lesson && !g.editing
+}
+
+// padButtonAt returns the pad button under a canvas position.
+func padButtonAt(x, y int) (int, bool) {
+	if y < padY || y >= padY+padH || x < padGap {
+		return 0, false
+	}
+	i := (x - padGap) / (padW + padGap)
+	if i >= len(padButtons) || (x-padGap)%(padW+padGap) >= padW {
+		return 0, false
+	}
+	return i, true
+}
+
+// updatePad runs the pad's keys, and a tap at x, y if tapped, and reports
+// whether either pressed a button.
+func (g *Game) updatePad(x, y int, tapped bool) bool {
+	for i, b := range padButtons {
+		if inpututil.IsKeyJustPressed(b.key) {
+			g.pressPad(i)
+			return true
+		}
+	}
+	if i, ok := padButtonAt(x, y); tapped && ok {
+		g.pressPad(i)
+		return true
+	}
+	return false
+}
+
+// pressPad presses pad button i.
+func (g *Game) pressPad(i int) {
+	b := padButtons[i]
+	if b.dx == 0 && b.dy == 0 {
+		px, py := g.renderSquare(g.padCursor.X, g.padCursor.Y)
+		g.clickBoard(px, py)
+		return
+	}
+	dx, dy := b.dx, b.dy
+	if g.flipped {
+		dx, dy = -dx, -dy
+	}
+	x, y := g.padCursor.X+dx, g.padCursor.Y+dy
+	if g.onBoard(x, y) {
+		g.padCursor = Pos{x, y}
+	}
+}
+
+// drawPad draws the pad's buttons along the bottom of the HUD and frames the
+// cursor's square on the board.
+func (g *Game) drawPad(screen *ebiten.Image) {
+	px, py := g.renderSquare(g.padCursor.X, g.padCursor.Y)
+	vector.StrokeRect(screen, float32(px)+1, float32(py)+1, tileSize-2, tileSize-2, 2, color.RGBA{255, 215, 0, 255}, false)
+	for i, b := range padButtons {
+		x := padGap + i*(padW+padGap)
+		vector.FillRect(screen, float32(x), float32(padY), padW, padH, color.RGBA{50, 50, 70, 255}, false)
+		vector.StrokeRect(screen, float32(x), float32(padY), padW, padH, 1, color.RGBA{150, 150, 150, 255}, false)
+		drawText(screen, b.label, x+(padW-7*len(b.label))/2, padY+16, color.White)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// standardFEN is the usual opening position; games from anywhere else carry
+// their first position in the PGN's SetUp and FEN tags.
+const standardFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// resultTag is the PGN result of the game: "1-0", "0-1", "1/2-1/2" for
+// every kind of draw, or "*" while it's still going.
+func (g *Game) resultTag() string {
+	switch {
+	case !g.gameOver:
+		return "*"
+	case g.winner == 1:
+		return "1-0"
+	case g.winner == 0:
+		return "0-1"
+	}
+	return "1/2-1/2"
+}
+
+// resultComment is the movetext comment saying how a finished game ended,
+// so draws in particular can be told apart. An armageddon draw says who it
+// went to.
+func (g *Game) resultComment() string {
+	c := g.endComment()
+	if g.armageddon && strings.HasPrefix(c, "{Draw") {
+		c = strings.TrimSuffix(c, "}") + ", a win for Black in armageddon}"
+	}
+	return c
+}
+
+// endComment is resultComment before any armageddon note.
+func (g *Game) endComment() string {
+	switch g.endReason {
+	case endCheckmate:
+		return "{Checkmate}"
+	case endStalemate:
+		return "{Draw by stalemate}"
+	case endDead:
+		return "{Draw by dead position}"
+	case endFiftyMove:
+		return "{Draw by fifty-move rule}"
+	case endRepetition:
+		return "{Draw by repetition}"
+	case endPerpetual:
+		return "{Draw by perpetual check}"
+	case endTime:
+		if g.winner < 0 || g.clock(Color(g.winner)) <= 0 { // or drawn, and armageddon gave it to Black
+			return "{Draw by timeout vs insufficient material}"
+		}
+		return "{Lost on time}"
+	}
+	return ""
+}
+
+// numberedMoves is the game's SAN moves with move numbers between them, as
+// tokens: "1.", "e4", "e5", and so on. A game started with Black to move
+// opens with "1...".
+func (g *Game) numberedMoves() []string {
+	var moves []string
+	first := g.moveCount - len(g.history)
+	for i, rec := range g.history {
+		n := first + i
+		switch {
+		case n%2 == 0:
+			moves = append(moves, fmt.Sprintf("%d.", n/2+1))
+		case i == 0:
+			moves = append(moves, fmt.Sprintf("%d...", n/2+1))
+		}
+		moves = append(moves, rec.SAN)
+	}
+	return moves
+}
+
+// PGN writes the game out with player as White and the hustler as Black.
+func (g *Game) PGN(player string) string {
+	var b strings.Builder
+	tag := func(name, value string) { fmt.Fprintf(&b, "[%s %q]\n", name, value) }
+	event := "Washington Square Park"
+	if g.rated {
+		event += ", rated"
+	}
+	tag("Event", event)
+	tag("Date", time.Now().Format("2006.01.02"))
+	tag("White", player)
+	tag("Black", g.hustlerName)
+	tag("Result", g.resultTag())
+	if g.chess960 {
+		tag("Variant", "Chess960")
+	}
+	if g.losAlamos {
+		tag("Variant", "Los Alamos")
+	}
+	if g.startFEN != standardFEN {
+		tag("SetUp", "1")
+		tag("FEN", g.startFEN)
+	}
+	b.WriteString("\n")
+
+	moves := g.numberedMoves()
+	if c := g.resultComment(); c != "" {
+		moves = append(moves, c)
+	}
+	moves = append(moves, g.resultTag())
+
+	line := 0
+	for i, m := range moves {
+		if i > 0 && line+1+len(m) > 79 {
+			b.WriteString("\n")
+			line = 0
+		} else if i > 0 {
+			b.WriteString(" ")
+			line++
+		}
+		b.WriteString(m)
+		line += len(m)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+var (
+	pgnFENTag    = regexp.MustCompile(`^\[FEN\s+"([^"]*)"\]`)
+	pgnAside     = regexp.MustCompile(`\{[^}]*\}|\([^()]*\)`) // comments and (unnested) variations
+	pgnMoveNum   = regexp.MustCompile(`^\d+\.+`)
+	pgnResultTag = map[string]bool{"1-0": true, "0-1": true, "1/2-1/2": true, "*": true}
+)
+
+// LoadPGN plays the first game in pgn onto a fresh board, or onto the
+// position in its FEN tag, leaving it ready to play on from the last move.
+// Comments, variations and annotation glyphs are skipped.
+func (g *Game) LoadPGN(pgn string) error {
+	fen := standardFEN
+	var text []string
+	for _, line := range strings.Split(pgn, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			if len(text) > 0 {
+				break // the next game's tags
+			}
+			if m := pgnFENTag.FindStringSubmatch(line); m != nil {
+				fen = m[1]
+			}
+			continue
+		}
+		if i := strings.IndexByte(line, ';'); i >= 0 {
+			line = line[:i]
+		}
+		if line != "" {
+			text = append(text, line)
+		}
+	}
+	if err := g.LoadFEN(fen); err != nil {
+		return fmt.Errorf("pgn: %w", err)
+	}
+
+	movetext := strings.Join(text, " ")
+	for pgnAside.MatchString(movetext) {
+		movetext = pgnAside.ReplaceAllString(movetext, " ")
+	}
+	demo := g.demo
+	g.demo = true // no promotion prompt, sound or move log while replaying
+	defer func() { g.demo = demo }()
+	for _, tok := range strings.Fields(movetext) {
+		tok = pgnMoveNum.ReplaceAllString(tok, "")
+		if tok == "" || pgnResultTag[tok] || strings.HasPrefix(tok, "$") {
+			continue
+		}
+		m, err := g.parseMove(tok)
+		if err != nil {
+			return fmt.Errorf("pgn: move %d %q: %v", g.moveCount/2+1, tok, err)
+		}
+		g.executeMove(m)
+	}
+	g.slide = slide{}
+	return nil
+}
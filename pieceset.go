@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// The sprite sheet is sheetCols by sheetRows tiles of tileSize pixels: the
+// pieces and the board squares, in the layout of the embedded chess.png. A
+// custom piece set is a PNG laid out the same way, named by the Pieces
+// setting.
+const sheetCols, sheetRows = 6, 4
+
+// sheetPath is the piece set sprites were cut from, "" for the embedded one.
+var sheetPath string
+
+// useSpriteSheet cuts sprites from the PNG at path, or from chess.png when
+// path is empty, unless that's the sheet already in use. A sheet that won't
+// load or isn't the right size is reported once and the embedded one is used
+// instead.
+func useSpriteSheet(path string) {
+	if sprites != nil && path == sheetPath {
+		return
+	}
+	sheetPath = path
+	img, err := decodeSheet(path)
+	if err != nil {
+		fmt.Println("pieces:", err)
+		img, err = decodeSheet("")
+	}
+	if err != nil {
+		panic("chess.png: " + err.Error())
+	}
+	sheet := ebiten.NewImageFromImage(img)
+	sprites = sprites[:0]
+	for y := 0; y < sheetRows; y++ {
+		for x := 0; x < sheetCols; x++ {
+			r := image.Rect(x*tileSize, y*tileSize, (x+1)*tileSize, (y+1)*tileSize).Add(img.Bounds().Min)
+			sprites = append(sprites, sheet.SubImage(r).(*ebiten.Image))
+		}
+	}
+}
+
+// decodeSheet reads the sprite sheet at path, or chess.png when path is
+// empty, and checks its size.
+func decodeSheet(path string) (image.Image, error) {
+	data, name := chessData, "chess.png"
+	if path != "" {
+		name = path
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != sheetCols*tileSize || h != sheetRows*tileSize {
+		return nil, fmt.Errorf("%s is %dx%d, want %dx%d", name, w, h, sheetCols*tileSize, sheetRows*tileSize)
+	}
+	return img, nil
+}
+
+// pieceSetName is the Pieces setting as the settings screen shows it: the
+// custom sheet's file name, cut to fit, or DEFAULT.
+func pieceSetName() string {
+	if profile.Settings.PieceSet == "" {
+		return "DEFAULT"
+	}
+	name := strings.ToUpper(strings.TrimSuffix(filepath.Base(profile.Settings.PieceSet), filepath.Ext(profile.Settings.PieceSet)))
+	return name[:min(len(name), 8)]
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// The post-mortem opens from the game-over screen and steps through the
+// game just played with the engine's verdict on each position: the score
+// and the line it would play from there. Nothing is searched until the
+// game is over, so the game itself stays unassisted.
+type postMortem struct {
+	frames []*Game // the start and the position after each move
+	sans   []string
+	ply    int
+	// Each position is searched the first time it's shown.
+	searched []bool
+	scores   []int // centipawns, White's advantage upward
+	lines    [][]Move
+}
+
+// analysisStyle weighs positions for the post-mortem: the hustler's terms
+// without his noise and his scholar's-mate script, so the verdict on a
+// position is the same each time.
+var analysisStyle = Personality{Name: "ANALYSIS", Material: 100, Mobility: 2, KingAttack: 12, KingShield: 3, Center: 8, PawnPush: 3}
+
+// openPostMortem replays the finished game into a post-mortem at its final
+// position.
+func (g *Game) openPostMortem() {
+	b, err := g.replayStart()
+	if err != nil {
+		fmt.Println("post-mortem:", err)
+		return
+	}
+	pm := &postMortem{frames: []*Game{b.clone()}}
+	for _, rec := range g.history {
+		m, err := b.parseMove(rec.SAN)
+		if err != nil {
+			fmt.Println("post-mortem:", rec.SAN+":", err)
+			return
+		}
+		b.executeMove(m)
+		pm.frames = append(pm.frames, b.clone())
+		pm.sans = append(pm.sans, rec.SAN)
+	}
+	n := len(pm.frames)
+	pm.searched, pm.scores, pm.lines = make([]bool, n), make([]int, n), make([][]Move, n)
+	g.postMortem = pm
+	pm.show(n - 1)
+}
+
+// replayStart sets up a board where g began, to replay its moves on. A
+// Chess960 start is loaded by placement alone, as its castling field
+// wouldn't load, and gets its rook files and full castling rights back. A
+// Los Alamos start gets its small board back, which LoadFEN clears.
+func (g *Game) replayStart() (*Game, error) {
+	b := NewGame(0, 0)
+	b.demo = true
+	fen := g.startFEN
+	if g.chess960 {
+		fen = strings.Join(strings.Fields(fen)[:2], " ")
+	}
+	if err := b.LoadFEN(fen); err != nil {
+		return nil, err
+	}
+	b.losAlamos = g.losAlamos
+	if g.chess960 {
+		b.chess960, b.rookFiles, b.castling = true, g.rookFiles, [2][2]bool{{true, true}, {true, true}}
+	}
+	return b, nil
+}
+
+// show moves the post-mortem to position ply, clamped, searching it if it
+// hasn't been already.
+func (pm *postMortem) show(ply int) {
+	pm.ply = max(0, min(ply, len(pm.frames)-1))
+	if pm.searched[pm.ply] {
+		return
+	}
+	pm.searched[pm.ply] = true
+	b := pm.frames[pm.ply].clone()
+	b.personality, b.opponent = &analysisStyle, nil
+	b.budget = searchBudgets[profile.Settings.SearchBudget].searchBudget
+	if _, score, ok := b.frankSearch(b.activeColor); ok {
+		if b.activeColor == Black {
+			score = -score
+		}
+		pm.scores[pm.ply], pm.lines[pm.ply] = score, b.lastSearch.pv
+	}
+}
+
+// updatePostMortem runs the post-mortem: Left and Right, or a tap on the
+// left or right half of the board, step through the moves, Home and End
+// jump to either end, and Escape or a tap on the HUD goes back to the
+// game-over screen.
+func (g *Game) updatePostMortem() {
+	pm := g.postMortem
+	if mx, my, ok := pointerJustPressed(); ok {
+		switch {
+		case my >= gridSize*tileSize:
+			g.postMortem = nil
+		case mx < screenW/2:
+			pm.show(pm.ply - 1)
+		default:
+			pm.show(pm.ply + 1)
+		}
+		return
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		g.postMortem = nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		pm.show(pm.ply - 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		pm.show(pm.ply + 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		pm.show(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		pm.show(len(pm.frames) - 1)
+	}
+}
+
+// evalText spells a post-mortem score in pawns from White's side, or as a
+// mate for whichever side has one.
+func evalText(score int) string {
+	switch {
+	case score > mateScore/2:
+		return "MATE"
+	case score < -mateScore/2:
+		return "-MATE"
+	}
+	return fmt.Sprintf("%+.2f", float64(score)/100)
+}
+
+// drawPostMortem draws the position shown with its best move framed on the
+// board, and the move played, the score and the best line in the HUD.
+func (g *Game) drawPostMortem(screen *ebiten.Image) {
+	pm := g.postMortem
+	b := pm.frames[pm.ply]
+	b.drawBoard(screen)
+	line := pm.lines[pm.ply]
+	gold := color.RGBA{255, 215, 0, 255}
+	if len(line) > 0 {
+		for _, sq := range []Pos{line[0].From, line[0].To} {
+			px, py := b.renderSquare(sq.X, sq.Y)
+			vector.StrokeRect(screen, float32(px)+1, float32(py)+1, tileSize-2, tileSize-2, 2, color.RGBA{0, 255, 150, 255}, false)
+		}
+	}
+	dy := gridSize * tileSize
+	vector.FillRect(screen, 0, float32(dy), 160, 40, color.RGBA{10, 10, 15, 255}, false)
+	move := "START"
+	if pm.ply > 0 {
+		move = fmt.Sprintf("%d/%d %s", pm.ply, len(pm.sans), pm.sans[pm.ply-1])
+	}
+	drawText(screen, move, 5, dy+12, gold)
+	best := "NO MOVES"
+	if len(line) > 0 {
+		eval := evalText(pm.scores[pm.ply])
+		drawText(screen, eval, screenW-5-7*len(eval), dy+12, color.White)
+		best = "BEST: " + b.lineText(line, false)
+	}
+	drawText(screen, best[:min(len(best), 22)], 5, dy+24, color.RGBA{0, 255, 150, 255})
+	drawText(screen, "<> HOME END ESC", 5, dy+36, color.RGBA{150, 150, 150, 255})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// The post-mortem rebuilds a game from replayStart and the SAN in its history,
+// so that has to land on the position the game ended in, whatever it
+// started from. The games castle whenever they can, to replay that too.
+func TestReplayRebuildsGame(t *testing.T) {
+	starts := []struct {
+		name string
+		game func() *Game
+	}{
+		{"standard", func() *Game { return NewGame(0, 0) }},
+		{"Chess960", func() *Game {
+			g := NewGame(0, 0)
+			g.chess960 = true
+			return g
+		}},
+		{"Los Alamos", func() *Game { return NewLosAlamosGame(0, 0) }},
+		{"FEN", func() *Game {
+			g := NewGame(0, 0)
+			if err := g.LoadFEN("r3k2r/1P3ppp/8/8/8/8/5PPP/R3K2R w KQkq - 0 1"); err != nil {
+				t.Fatal(err)
+			}
+			return g
+		}},
+	}
+	for _, s := range starts {
+		for seed := int64(0); seed < 5; seed++ {
+			g := s.game()
+			g.demo, g.rng = true, rand.New(rand.NewSource(seed))
+			if g.chess960 {
+				g.rebuildBoard() // a back rank from the seeded rng
+			}
+			for ply := 0; ply < 16; ply++ {
+				moves := g.LegalMoves(g.activeColor)
+				if len(moves) == 0 {
+					break
+				}
+				m := moves[g.rng.Intn(len(moves))]
+				for _, c := range moves {
+					if p, q := g.board[c.From.Y][c.From.X], g.board[c.To.Y][c.To.X]; p.Type == King && (abs(c.To.X-c.From.X) > 1 || q != nil && q.Color == p.Color) {
+						m = c
+					}
+				}
+				g.executeMove(m)
+			}
+
+			b, err := g.replayStart()
+			if err != nil {
+				t.Fatalf("%s, seed %d: %v", s.name, seed, err)
+			}
+			for _, rec := range g.history {
+				m, err := b.parseMove(rec.SAN)
+				if err != nil {
+					t.Fatalf("%s, seed %d: %s: %v", s.name, seed, rec.SAN, err)
+				}
+				b.executeMove(m)
+			}
+			if got, want := b.FEN(), g.FEN(); got != want {
+				t.Errorf("%s, seed %d: replay ends at %s, want %s", s.name, seed, got, want)
+			}
+		}
+	}
+}
+
+func TestEvalText(t *testing.T) {
+	for _, tt := range []struct {
+		score int
+		want  string
+	}{
+		{mateScore + 3, "MATE"},
+		{-mateScore - 1, "-MATE"},
+		{125, "+1.25"},
+		{-40, "-0.40"},
+		{0, "+0.00"},
+	} {
+		if got := evalText(tt.score); got != tt.want {
+			t.Errorf("evalText(%d) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
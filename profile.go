@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const defaultProfileName = "PLAYER"
+
+// Profile is one player's bankroll and lifetime record, saved between runs
+// so several people can hustle Frank on the same machine.
+type Profile struct {
+	Name   string `json:"name"`
+	Wallet int    `json:"wallet"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+
+	// Rated games are kept apart from the record above.
+	RatedWins   int `json:"ratedWins"`
+	RatedLosses int `json:"ratedLosses"`
+	RatedDraws  int `json:"ratedDraws"`
+
+	Abandoned int  `json:"abandoned"` // staked games closed before they ended
+	Bailed    bool `json:"bailed"`    // the last one was closed while behind
+	Tutored   bool `json:"tutored"`   // has seen or skipped the first-run tutorial
+
+	Settings Settings `json:"settings"`
+}
+
+// Settings are a profile's gameplay preferences.
+type Settings struct {
+	ConfirmMoves  bool   `json:"confirmMoves"`  // a second click confirms each move
+	TimeControl   int    `json:"timeControl"`   // index into timeControls
+	DrawCounters  bool   `json:"drawCounters"`  // show the 50-move and repetition counts
+	TimeOdds      int    `json:"timeOdds"`      // index into timeOdds
+	HighContrast  bool   `json:"highContrast"`  // outline highlights instead of tinting
+	HideMoney     bool   `json:"hideMoney"`     // keep the wallet and stakes off screen
+	VisualCues    bool   `json:"visualCues"`    // flash the board frame for moves, checks and low time
+	AutoQueen     bool   `json:"autoQueen"`     // promote to a queen unless Shift is held
+	SearchBudget  int    `json:"searchBudget"`  // index into searchBudgets
+	HangingPieces bool   `json:"hangingPieces"` // outline the player's pieces that can be won
+	Font          int    `json:"font"`          // index into uiFaces
+	StartingCash  int    `json:"startingCash"`  // index into startingCash
+	PayoutRate    int    `json:"payoutRate"`    // index into payoutRates
+	EasyAssist    bool   `json:"easyAssist"`    // offer a free takeback when Frank punishes a blunder
+	TouchPad      bool   `json:"touchPad"`      // big on-screen buttons drive a board cursor
+	MoveMarks     int    `json:"moveMarks"`     // index into moveMarkNames
+	PieceSet      string `json:"pieceSet"`      // a custom sprite sheet's path, or "" for chess.png
+}
+
+// timeControls are the per-move clock credits a profile can pick from.
+var timeControls = []struct {
+	name      string
+	secs      int
+	bronstein bool
+}{
+	{"PLAIN", 0, false},
+	{"+2s", 2, false},
+	{"2s DELAY", 2, true},
+}
+
+// timeOdds are the handicaps on the hustler's clock a profile can pick from:
+// he starts with the game's minutes divided by div, the player with all of
+// them.
+var timeOdds = []struct {
+	name string
+	div  int
+}{
+	{"EVEN", 1},
+	{"1/2", 2},
+	{"1/5", 5},
+}
+
+// searchBudgets are the limits on Frank's search a profile can pick from,
+// trading his strength against how long he takes on a slow machine.
+var searchBudgets = []struct {
+	name string
+	searchBudget
+}{
+	{"NORMAL", searchBudget{}},
+	{"DEPTH 1", searchBudget{depth: 1}},
+	{"DEPTH 3", searchBudget{depth: 3}},
+	{"100ms", searchBudget{millis: 100}},
+	{"500ms", searchBudget{millis: 500}},
+}
+
+// startingCash are the wallets a profile can start out, or start over, with.
+var startingCash = []struct {
+	name   string
+	amount int
+}{
+	{"$100", 100},
+	{"$250", 250},
+	{"$1000", 1000},
+	{"$50", 50},
+	{"$20", 20},
+}
+
+// payoutRates scale what a win collects, as a percent of the usual payout.
+// Losing still costs the whole wager.
+var payoutRates = []struct {
+	name    string
+	percent int
+}{
+	{"x1", 100},
+	{"x1.5", 150},
+	{"x2", 200},
+	{"x0.75", 75},
+	{"x0.5", 50},
+}
+
+func newProfile(name string) *Profile {
+	return &Profile{Name: name, Wallet: startingCash[0].amount}
+}
+
+// profileDir is where profile files live, one JSON file per name.
+func profileDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "wsp-chess", "profiles"), nil
+}
+
+// loadProfile reads the named profile, or starts a fresh one if it has never
+// been saved (or can't be read).
+func loadProfile(name string) *Profile {
+	p := newProfile(name)
+	dir, err := profileDir()
+	if err != nil {
+		return p
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+	if err != nil {
+		return p
+	}
+	p.Tutored = true // saved before the tutorial existed, so not a first run
+	if err := json.Unmarshal(data, p); err != nil {
+		fmt.Printf("profile %s: %v\n", name, err)
+		return newProfile(name)
+	}
+	p.Name = name
+	if p.Settings.TimeControl < 0 || p.Settings.TimeControl >= len(timeControls) {
+		p.Settings.TimeControl = 0
+	}
+	if p.Settings.TimeOdds < 0 || p.Settings.TimeOdds >= len(timeOdds) {
+		p.Settings.TimeOdds = 0
+	}
+	if p.Settings.SearchBudget < 0 || p.Settings.SearchBudget >= len(searchBudgets) {
+		p.Settings.SearchBudget = 0
+	}
+	if p.Settings.StartingCash < 0 || p.Settings.StartingCash >= len(startingCash) {
+		p.Settings.StartingCash = 0
+	}
+	if p.Settings.PayoutRate < 0 || p.Settings.PayoutRate >= len(payoutRates) {
+		p.Settings.PayoutRate = 0
+	}
+	return p
+}
+
+// save writes the profile and marks it as the one to load next launch.
+func (p *Profile) save() {
+	dir, err := profileDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err == nil {
+		var data []byte
+		data, err = json.MarshalIndent(p, "", "  ")
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, p.Name+".json"), data, 0o644)
+		}
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, "last"), []byte(p.Name), 0o644)
+	}
+	if err != nil {
+		fmt.Printf("profile %s: %v\n", p.Name, err)
+	}
+}
+
+// reset puts the record back to a brand-new player's and the wallet to the
+// starting cash the settings ask for. Settings are preferences, not
+// progress, so they survive.
+func (p *Profile) reset() {
+	settings := p.Settings
+	*p = *newProfile(p.Name)
+	p.Settings = settings
+	p.Wallet = startingCash[settings.StartingCash].amount
+	p.save()
+}
+
+// settle books a finished game: winner is 1 if the human won, collecting
+// payout, 0 if Frank did, taking the wager, and -1 for a draw, which leaves
+// the wallet alone. Unstaked games, like positions from the board editor,
+// aren't booked at all. A rated game goes on the rated record.
+func (p *Profile) settle(winner, wager, payout int, rated bool) {
+	if wager == 0 {
+		return
+	}
+	wins, losses, draws := &p.Wins, &p.Losses, &p.Draws
+	if rated {
+		wins, losses, draws = &p.RatedWins, &p.RatedLosses, &p.RatedDraws
+	}
+	switch winner {
+	case 1:
+		*wins++
+		p.Wallet += payout
+	case 0:
+		*losses++
+		p.Wallet -= wager
+	default:
+		*draws++
+	}
+	p.save()
+}
+
+// abandon books a staked game the player closed the window on mid-game.
+func (p *Profile) abandon(losing bool) {
+	p.Abandoned++
+	p.Bailed = losing
+	p.save()
+}
+
+// listProfiles returns the saved profile names in alphabetical order.
+func listProfiles() []string {
+	dir, err := profileDir()
+	if err != nil {
+		return nil
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	var names []string
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(f), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lastProfile loads whoever played last, falling back to the default player
+// when nobody has or the saved name isn't one a player could have typed.
+func lastProfile() *Profile {
+	name := defaultProfileName
+	if dir, err := profileDir(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(dir, "last")); err == nil {
+			last := strings.TrimSpace(string(data))
+			invalid := func(r rune) bool { return !validProfileName(r) }
+			if last != "" && !strings.ContainsFunc(last, invalid) {
+				name = last
+			}
+		}
+	}
+	return loadProfile(name)
+}
+
+// nextProfile cycles to the saved profile after the current one.
+func nextProfile(cur string) *Profile {
+	names := listProfiles()
+	for i, n := range names {
+		if n == cur {
+			return loadProfile(names[(i+1)%len(names)])
+		}
+	}
+	if len(names) > 0 {
+		return loadProfile(names[0])
+	}
+	return loadProfile(cur)
+}
+
+// validProfileName keeps names short and safe to use as file names.
+func validProfileName(r rune) bool {
+	return (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_'
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLastProfileName(t *testing.T) {
+	tests := []struct{ last, want string }{
+		{"FRANKIE\n", "FRANKIE"},
+		{"", defaultProfileName},
+		{"../../evil", defaultProfileName},
+		{"lower", defaultProfileName},
+	}
+	for _, tt := range tests {
+		// os.UserConfigDir reads one of these, depending on the OS.
+		home := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", home)
+		t.Setenv("HOME", home)
+		t.Setenv("AppData", home)
+		dir, err := profileDir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "last"), []byte(tt.last), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := lastProfile().Name; got != tt.want {
+			t.Errorf("last = %q: profile %q, want %q", tt.last, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+//go:embed puzzles.json
+var puzzleData []byte
+
+// Puzzle is a position with White to move: a mate in N, or, with MateIn 0,
+// a line the player has to find move for move, like the ones imported from
+// Lichess.
+type Puzzle struct {
+	Name     string   `json:"name"`
+	FEN      string   `json:"fen"`
+	MateIn   int      `json:"mateIn"`
+	Solution []string `json:"solution"` // long algebraic, both sides' moves
+	Rating   int      `json:"rating,omitempty"`
+	Themes   []string `json:"themes,omitempty"`
+}
+
+var puzzles = mustLoadPuzzles()
+
+func mustLoadPuzzles() []Puzzle {
+	var ps []Puzzle
+	if err := json.Unmarshal(puzzleData, &ps); err != nil {
+		panic("puzzles.json: " + err.Error())
+	}
+	return ps
+}
+
+// NewPuzzleGame sets up puzzle i. Puzzles have no clock and no stakes.
+func NewPuzzleGame(i int) *Game {
+	g := NewGame(0, 0)
+	g.puzzle, g.puzzleIdx = &puzzles[i], i
+	if err := g.LoadFEN(g.puzzle.FEN); err != nil {
+		panic(g.puzzle.Name + ": " + err.Error())
+	}
+	g.currentDialog = "Find the mate! ESC:menu"
+	if g.puzzle.MateIn == 0 {
+		g.currentDialog = "Best move? ESC:menu"
+	}
+	return g
+}
+
+// judgePuzzleMove scores the human's latest move. Any move that still forces
+// mate within the puzzle's count is accepted, not just the listed one, and
+// Frank answers with the listed reply while the player stays on the line.
+func (g *Game) judgePuzzleMove() {
+	if g.puzzle.MateIn == 0 {
+		g.judgeLineMove()
+		return
+	}
+	onLine := g.puzzleStep < len(g.puzzle.Solution) && g.puzzle.Solution[g.puzzleStep] == g.lastUCI()
+	movesLeft := g.puzzle.MateIn - g.puzzleStep/2 - 1
+	switch {
+	case !g.hasLegalMoves(Black) && g.isInCheck(Black):
+		g.puzzleDone, g.puzzleSolved = true, true
+		g.currentDialog = "SOLVED! Click: next"
+	case movesLeft > 0 && g.defenceFails(movesLeft):
+		g.puzzleStep++
+		g.puzzleOnLine = onLine
+	default:
+		g.puzzleDone = true
+		g.currentDialog = "WRONG. Click: retry"
+		g.puzzleAnswer = g.solutionSAN()
+	}
+}
+
+// judgeLineMove scores the human's latest move in a puzzle with no mate
+// count. It has to be the listed move, unless it mates, which Lichess
+// accepts too.
+func (g *Game) judgeLineMove() {
+	listed := g.puzzle.Solution[g.puzzleStep] == g.lastUCI()
+	switch {
+	case !g.hasLegalMoves(Black) && g.isInCheck(Black), listed && g.puzzleStep+1 == len(g.puzzle.Solution):
+		g.puzzleDone, g.puzzleSolved = true, true
+		g.currentDialog = "SOLVED! Click: next"
+	case listed:
+		g.puzzleStep++
+		g.puzzleOnLine = true
+	default:
+		g.puzzleDone = true
+		g.currentDialog = "WRONG. Click: retry"
+		g.puzzleAnswer = g.solutionSAN()
+	}
+}
+
+// puzzleReply is Frank's answer to a correct, non-final puzzle move.
+func (g *Game) puzzleReply() (Move, bool) {
+	if g.puzzleOnLine {
+		if m, ok := parseUCI(g.puzzle.Solution[g.puzzleStep]); ok {
+			g.puzzleStep++
+			return m, true
+		}
+	}
+	g.puzzleStep++
+	return g.frankMove(Black)
+}
+
+// solutionSAN spells out the puzzle's listed line in SAN.
+func (g *Game) solutionSAN() string {
+	c := NewGame(0, 0)
+	c.demo = true
+	c.LoadFEN(g.puzzle.FEN)
+	var line []string
+	for _, uci := range g.puzzle.Solution {
+		m, ok := parseUCI(uci)
+		if !ok {
+			break
+		}
+		c.executeMove(m)
+		line = append(line, c.history[len(c.history)-1].SAN)
+	}
+	return strings.Join(line, " ")
+}
+
+// forcedMate reports whether the side to move can force checkmate within n
+// of its own moves.
+func (g *Game) forcedMate(n int) bool {
+	for _, m := range g.LegalMoves(g.activeColor) {
+		c := g.clone()
+		c.executeMove(m)
+		if c.defenceFails(n - 1) {
+			return true
+		}
+	}
+	return false
+}
+
+// defenceFails reports whether the side to move is mated now, or will be
+// within n further moves of the attacker whatever it plays.
+func (g *Game) defenceFails(n int) bool {
+	if !g.hasLegalMoves(g.activeColor) {
+		return g.isInCheck(g.activeColor)
+	}
+	if n == 0 {
+		return false
+	}
+	for _, m := range g.LegalMoves(g.activeColor) {
+		c := g.clone()
+		c.executeMove(m)
+		if !c.forcedMate(n) {
+			return false
+		}
+	}
+	return true
+}
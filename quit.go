@@ -0,0 +1,56 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// staked reports whether a game with money on it is under way, so that
+// walking away from it has to be booked.
+func (g *Game) staked() bool {
+	return g.gameStarted && !g.gameOver && g.wager > 0 && g.puzzle == nil
+}
+
+// updateQuit handles the window's close button and returns ebiten.Termination
+// once the game should exit. Leaving a staked game is confirmed first, with
+// play held while the player decides, except in bullet: there closing the
+// window forfeits on the spot, so it can't be used to stop the clock.
+// Everything else the profile holds is saved as it changes.
+func (g *Game) updateQuit() error {
+	if ebiten.IsWindowBeingClosed() {
+		switch {
+		case !g.staked():
+			return ebiten.Termination
+		case g.initialMins <= bulletMins:
+			g.winner = 0
+			profile.settle(g.winner, g.wager, g.payout(), g.rated)
+			g.settleBet()
+			return ebiten.Termination
+		case g.confirmQuit: // closed again while we were asking
+			profile.abandon(g.material(White) < g.material(Black))
+			return ebiten.Termination
+		}
+		g.confirmQuit = true
+		return nil
+	}
+	if !g.confirmQuit {
+		return nil
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyY) || inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		profile.abandon(g.material(White) < g.material(Black))
+		return ebiten.Termination
+	case inpututil.IsKeyJustPressed(ebiten.KeyN) || inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		g.confirmQuit = false
+	}
+	return nil
+}
+
+func (g *Game) drawConfirmQuit(screen *ebiten.Image) {
+	vector.FillRect(screen, 10, 60, 140, 40, color.RGBA{0, 0, 0, 240}, false)
+	drawText(screen, "LEAVE THE TABLE?", 24, 77, color.White)
+	drawText(screen, "Y:leave N:stay", 31, 92, color.RGBA{150, 150, 150, 255})
+}
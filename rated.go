@@ -0,0 +1,13 @@
+package main
+
+// rated is the start menu's rated mode, for playing the hustler straight: a
+// rated game has no assists and goes on the profile's rated record, apart
+// from the casual one.
+var rated bool
+
+// assists reports whether the player gets help in this game: takebacks,
+// bought or free, and the threat and hanging-piece overlays. A rated game
+// gets none.
+func (g *Game) assists() bool {
+	return !g.rated
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// reviewGame is a finished game kept for the review screen, which steps
+// through it a position at a time. Nothing is written to disk; the list only
+// lasts the session.
+type reviewGame struct {
+	title     string   // the opponent and the result
+	positions []string // as Game.positions
+	sans      []string // the move leading to each position after the first
+}
+
+const maxReviewGames = 5
+
+var recentGames []reviewGame // newest first
+
+// keepForReview adds a finished game to the front of recentGames.
+func (g *Game) keepForReview() {
+	sans := make([]string, len(g.history))
+	for i, rec := range g.history {
+		sans[i] = rec.SAN
+	}
+	who := g.rival().Short
+	if g.hotseat {
+		who = "HOT SEAT"
+	}
+	rg := reviewGame{who + " " + g.resultTag(), slices.Clone(g.positions), sans}
+	recentGames = append([]reviewGame{rg}, recentGames...)
+	if len(recentGames) > maxReviewGames {
+		recentGames = recentGames[:maxReviewGames]
+	}
+}
+
+// showReview sets the review board to position ply of game idx, both clamped.
+func (g *Game) showReview(idx, ply int) {
+	idx = (idx + len(recentGames)) % len(recentGames)
+	rg := recentGames[idx]
+	g.reviewIdx, g.reviewPly = idx, max(0, min(ply, len(rg.positions)-1))
+	// Only placement and side to move: the castling field of a Chess960
+	// game wouldn't load, and the board doesn't need it.
+	b := NewGame(0, 0)
+	b.LoadFEN(strings.Join(strings.Fields(rg.positions[g.reviewPly])[:2], " "))
+	g.reviewBoard = b
+}
+
+// branch starts an unstaked game against the hustler picked on the menu from
+// position ply of rg, with the moves leading there as its history. The clocks
+// start fresh rather than where the original game had them.
+func (rg reviewGame) branch(ply int) (*Game, error) {
+	g := newPositionGame()
+	if err := g.LoadFEN(rg.positions[0]); err != nil {
+		return nil, err
+	}
+	g.demo = true // no promotion prompt, sound or move log while replaying
+	for _, san := range rg.sans[:ply] {
+		m, err := g.parseMove(san)
+		if err != nil {
+			return nil, err
+		}
+		g.executeMove(m)
+	}
+	g.demo, g.slide = false, slide{}
+	return g, nil
+}
+
+// updateReview runs the review screen: Left and Right step through the moves,
+// Up and Down rotate through the games, Enter branches off into a new game
+// from the position shown, and Escape or G goes back.
+func (g *Game) updateReview() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyG):
+		g.reviewBoard = nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		ng, err := recentGames[g.reviewIdx].branch(g.reviewPly)
+		if err != nil {
+			fmt.Println("branch:", err)
+			g.reviewBoard, g.menuNote = nil, "CAN'T BRANCH HERE"
+			return
+		}
+		*g = *ng
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		g.showReview(g.reviewIdx, g.reviewPly-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		g.showReview(g.reviewIdx, g.reviewPly+1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		g.showReview(g.reviewIdx-1, 0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		g.showReview(g.reviewIdx+1, 0)
+	}
+}
+
+func (g *Game) drawReview(screen *ebiten.Image) {
+	g.reviewBoard.drawBoard(screen)
+	dy := gridSize * tileSize
+	vector.FillRect(screen, 0, float32(dy), 160, 40, color.RGBA{10, 10, 15, 255}, false)
+	rg := recentGames[g.reviewIdx]
+	drawText(screen, fmt.Sprintf("%d/%d %s", g.reviewIdx+1, len(recentGames), rg.title), 5, dy+12, color.White)
+	move := "START"
+	if g.reviewPly > 0 {
+		move = fmt.Sprintf("%d/%d %s", g.reviewPly, len(rg.sans), rg.sans[g.reviewPly-1])
+	}
+	drawText(screen, move, 5, dy+24, color.RGBA{255, 215, 0, 255})
+	drawText(screen, "<>^v ENTER:play ESC", 5, dy+36, color.RGBA{150, 150, 150, 255})
+}
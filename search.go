@@ -0,0 +1,300 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// Personality is one of Frank's playing styles: the weights his search puts
+// on each term of the leaf evaluation, in centipawns per unit.
+type Personality struct {
+	Name       string
+	Material   int  // percent of the standard piece values
+	Mobility   int  // per square a piece can move to
+	KingAttack int  // per move landing next to the enemy king
+	KingShield int  // per pawn sheltering our own king
+	Center     int  // per piece on the four central squares
+	PawnPush   int  // per rank a pawn has advanced
+	Noise      int  // random spread added to each root move
+	Trap       bool // try the scholar's-mate trap first
+}
+
+var personalities = []Personality{
+	{Name: "HUSTLER", Material: 100, Mobility: 2, KingAttack: 12, KingShield: 3, Center: 8, PawnPush: 3, Noise: 40, Trap: true},
+	{Name: "ATTACKER", Material: 90, Mobility: 4, KingAttack: 25, Center: 6, PawnPush: 5, Noise: 10},
+	{Name: "MATERIALIST", Material: 120, Noise: 5},
+	{Name: "GRINDER", Material: 100, Mobility: 3, KingAttack: 2, KingShield: 15, Center: 4, PawnPush: 1, Noise: 5},
+}
+
+const (
+	searchDepth    = 2
+	maxSearchDepth = 6 // how deep a time budget may take him
+	mateScore      = 100000
+)
+
+// searchBudget limits Frank's search. A zero depth means the opponent's own.
+// A nonzero millis overrides it: he deepens a ply at a time and plays the
+// deepest search that finished in time.
+type searchBudget struct {
+	depth, millis int
+}
+
+// searchStats is what one search did, for the verbose log and UCI's info line.
+type searchStats struct {
+	nodes, depth int
+	deadline     time.Time // zero without a time budget
+	stopped      bool      // the deadline passed mid-search
+	pv           []Move    // the line he expects, his move first
+}
+
+// outOfTime counts a node and reports whether the search should give up.
+// The clock is only read every 64 nodes, as a node costs far less.
+func (st *searchStats) outOfTime() bool {
+	st.nodes++
+	if !st.stopped && !st.deadline.IsZero() && st.nodes%64 == 0 && time.Now().After(st.deadline) {
+		st.stopped = true
+	}
+	return st.stopped
+}
+
+type searchMove struct {
+	Move
+	order int
+}
+
+// style is the personality Frank plays this game with.
+func (g *Game) style() *Personality {
+	if g.personality == nil {
+		return &personalities[0]
+	}
+	return g.personality
+}
+
+// frankMove picks Frank's move for color c without touching the board.
+// It knows nothing about who is clicking, so it can play either side.
+func (g *Game) frankMove(c Color) (Move, bool) {
+	m, _, ok := g.frankSearch(c)
+	return m, ok
+}
+
+// frankSearch is frankMove that also returns the score Frank's search gave
+// the move, in centipawns for c. Scripted moves aren't searched and get the
+// static evaluation of the position instead.
+func (g *Game) frankSearch(c Color) (Move, int, bool) {
+	s := g.style()
+	if s.Trap {
+		// SCHOLAR'S MATE (STILL PRIORITIZED)
+		script := []Move{{From: Pos{4, 1}, To: Pos{4, 3}}, {From: Pos{3, 0}, To: Pos{7, 4}}, {From: Pos{5, 0}, To: Pos{2, 3}}, {From: Pos{7, 4}, To: Pos{5, 6}}}
+		for _, m := range script {
+			if c == White {
+				m.From.Y, m.To.Y = 7-m.From.Y, 7-m.To.Y
+			}
+			if p := g.board[m.From.Y][m.From.X]; p != nil && p.Color == c && g.isMoveLegal(p, m) && g.isMoveSafe(m) {
+				g.lastSearch = searchStats{pv: []Move{m}}
+				return m, g.evaluate(c, s), true
+			}
+		}
+	}
+
+	moves := g.searchMoves(c)
+	if len(moves) == 0 {
+		return Move{}, 0, false
+	}
+	var st searchStats
+	trouble := g.inTimeTrouble(c)
+	if g.budget.millis == 0 || trouble {
+		st.depth = g.rival().Depth + g.stakesDepth
+		switch {
+		case trouble:
+			st.depth = 1
+		case g.budget.depth > 0:
+			st.depth = g.budget.depth
+		}
+		pv, score := g.searchRoot(moves, st.depth, s, &st)
+		st.pv = pv
+		g.lastSearch = st
+		return pv[0], score, true
+	}
+	st.deadline = time.Now().Add(time.Duration(g.budget.millis) * time.Millisecond)
+	pv, score := []Move{moves[0].Move}, g.evaluate(c, s)
+	for d := 1; d <= maxSearchDepth; d++ {
+		line, sc := g.searchRoot(moves, d, s, &st)
+		if st.stopped {
+			break
+		}
+		pv, score, st.depth = line, sc, d
+	}
+	st.pv = pv
+	g.lastSearch = st
+	return pv[0], score, true
+}
+
+// searchRoot searches each of the root moves depth plies deep and returns
+// the principal variation, the best move and the replies expected to it,
+// with its score.
+func (g *Game) searchRoot(moves []searchMove, depth int, s *Personality, st *searchStats) ([]Move, int) {
+	pv, bestScore := []Move{moves[0].Move}, -2*mateScore
+	for _, m := range moves {
+		n := g.clone()
+		n.executeMove(m.Move)
+		// Noise can lift a move by at most s.Noise, so anything that can't
+		// come within that of the best so far may be cut off early.
+		var line []Move
+		score := -n.negamax(depth-1, -2*mateScore, -(bestScore - s.Noise), s, st, &line)
+		if s.Noise > 0 {
+			score += g.rng.Intn(s.Noise + 1)
+		}
+		if score > bestScore {
+			pv, bestScore = append([]Move{m.Move}, line...), score
+		}
+	}
+	return pv, bestScore
+}
+
+// negamax scores the position for the side to move, depth plies deep, and
+// sets *pv to the line that earns the score when one beats alpha. Each ply
+// builds its line from the one below, the triangular PV table kept on the
+// stack. Once st runs out of time the scores are meaningless and the caller
+// drops them.
+func (g *Game) negamax(depth, alpha, beta int, s *Personality, st *searchStats, pv *[]Move) int {
+	if st.outOfTime() {
+		return 0
+	}
+	c := g.activeColor
+	moves := g.searchMoves(c)
+	if len(moves) == 0 {
+		if g.isInCheck(c) {
+			return -mateScore - depth // mated sooner is worse
+		}
+		return 0
+	}
+	if depth == 0 {
+		return g.evaluate(c, s)
+	}
+	for _, m := range moves {
+		n := g.clone()
+		n.executeMove(m.Move)
+		var line []Move
+		if score := -n.negamax(depth-1, -beta, -alpha, s, st, &line); score > alpha {
+			alpha = score
+			*pv = append([]Move{m.Move}, line...)
+			if alpha >= beta {
+				break
+			}
+		}
+	}
+	return alpha
+}
+
+// searchMoves lists c's legal moves, winning captures first and captures
+// that lose material by static exchange last.
+func (g *Game) searchMoves(c Color) []searchMove {
+	var moves []searchMove
+	for _, m := range g.LegalMoves(c) {
+		order := 0
+		if t := g.board[m.To.Y][m.To.X]; t != nil && t.Color != c {
+			order = g.see(m)*10 + 1
+		}
+		moves = append(moves, searchMove{m, order})
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].order > moves[j].order })
+	return moves
+}
+
+// evaluate scores the position for c with the personality's weights.
+func (g *Game) evaluate(c Color, s *Personality) int {
+	return g.sideScore(c, s) - g.sideScore(1-c, s)
+}
+
+func (g *Game) sideScore(c Color, s *Personality) int {
+	kx, ky, ex, ey := -1, -1, -1, -1
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if p := g.board[y][x]; p != nil && p.Type == King {
+				if p.Color == c {
+					kx, ky = x, y
+				} else {
+					ex, ey = x, y
+				}
+			}
+		}
+	}
+	forward := -1
+	if c == Black {
+		forward = 1
+	}
+
+	score := 0
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			p := g.board[y][x]
+			if p == nil || p.Color != c {
+				continue
+			}
+			if (x == 3 || x == 4) && (y == 3 || y == 4) {
+				score += s.Center
+			}
+			switch p.Type {
+			case King:
+				continue
+			case Pawn:
+				start := 6
+				if c == Black {
+					start = 1
+				}
+				score += abs(y-start) * s.PawnPush
+				if abs(x-kx) <= 1 && (y == ky+forward || y == ky+2*forward) {
+					score += s.KingShield
+				}
+			default:
+				if s.Mobility == 0 && s.KingAttack == 0 {
+					break
+				}
+				for ty := 0; ty < 8; ty++ {
+					for tx := 0; tx < 8; tx++ {
+						if g.isMoveLegal(p, Move{From: Pos{x, y}, To: Pos{tx, ty}}) {
+							score += s.Mobility
+							if abs(tx-ex) <= 1 && abs(ty-ey) <= 1 {
+								score += s.KingAttack
+							}
+						}
+					}
+				}
+			}
+			score += pieceValues[p.Type] * s.Material
+		}
+	}
+	return score
+}
+
+// inTimeTrouble reports whether c is down to the last few seconds of a
+// clocked game. Frank then plays at a glance: one ply deep and all but at
+// once, trading the quality of his moves for not losing on time.
+func (g *Game) inTimeTrouble(c Color) bool {
+	return g.initialMins > 0 && g.clock(c) < lowTimeMark
+}
+
+// thinkFrames is how long Frank sits over his move before playing it, so he
+// uses his clock the way a person would: a couple of seconds for a routine
+// move, up to four when he has many choices or many captures to weigh, and
+// barely a pause when the move is forced or he's in time trouble.
+func (g *Game) thinkFrames(c Color) int {
+	if g.inTimeTrouble(c) {
+		return 6
+	}
+	moves := g.LegalMoves(c)
+	if len(moves) <= 1 {
+		return 15
+	}
+	captures := 0
+	for _, m := range moves {
+		if g.board[m.To.Y][m.To.X] != nil || (g.board[m.From.Y][m.From.X].Type == Pawn && m.To.X == g.epX && m.To.Y == g.epY) {
+			captures++
+		}
+	}
+	frames := 30 + 3*len(moves) + 15*captures
+	if g.moveCount < 6 { // book-ish opening moves come quickly
+		frames /= 2
+	}
+	return min(frames, 240)
+}
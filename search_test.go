@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+// checkPV replays line from g and fails unless every move in it is legal.
+// It returns the game at the end of the line.
+func checkPV(t *testing.T, g *Game, line []Move) *Game {
+	t.Helper()
+	c := g.clone()
+	for i, m := range line {
+		p := c.board[m.From.Y][m.From.X]
+		if p == nil || p.Color != c.activeColor || !c.isMoveLegal(p, m) || !c.isMoveSafe(m) {
+			t.Fatalf("PV move %d, %s%s, is illegal in %s", i, toAlg(m.From.X, m.From.Y), toAlg(m.To.X, m.To.Y), c.FEN())
+		}
+		c.executeMove(m)
+	}
+	return c
+}
+
+func TestPVMateInTwo(t *testing.T) {
+	g := NewGame(0, 0)
+	g.demo = true
+	if err := g.LoadFEN("k7/8/2K5/8/8/8/8/7R w - - 0 1"); err != nil { // Kb6 Kb8 Rh8#
+		t.Fatal(err)
+	}
+	g.personality = &Personality{Name: "TEST", Material: 100} // no noise
+	g.budget.depth = 3
+	m, ok := g.frankMove(White)
+	if !ok {
+		t.Fatal("no move")
+	}
+	pv := g.lastSearch.pv
+	if len(pv) == 0 || pv[0] != m {
+		t.Fatalf("PV %v doesn't start with the move played, %v", pv, m)
+	}
+	end := checkPV(t, g, pv)
+	if end.hasLegalMoves(end.activeColor) || !end.isInCheck(end.activeColor) {
+		t.Errorf("PV %s doesn't end in mate: %s", g.lineText(pv, false), end.FEN())
+	}
+}
+
+// A timed search that runs out partway through a depth plays the deepest
+// completed one, and its line has to be that depth's too.
+func TestPVKeptWhenTimedSearchStops(t *testing.T) {
+	g := NewGame(0, 0)
+	g.demo = true
+	g.personality = &Personality{Name: "TEST", Material: 100, Mobility: 2}
+	g.budget.millis = 30
+	m, ok := g.frankMove(White)
+	if !ok {
+		t.Fatal("no move")
+	}
+	st := g.lastSearch
+	if !st.stopped {
+		t.Skip("every depth finished in time, so nothing was stopped")
+	}
+	if len(st.pv) == 0 || st.pv[0] != m {
+		t.Fatalf("PV %v doesn't start with the move played, %v", st.pv, m)
+	}
+	if len(st.pv) > st.depth {
+		t.Errorf("PV %s is longer than the depth %d it came from", g.lineText(st.pv, false), st.depth)
+	}
+	checkPV(t, g, st.pv)
+}
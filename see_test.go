@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		name, fen, move string
+		want            int
+	}{
+		// exd5 exd5: a pawn for a knight.
+		{"pawn takes defended knight", "4k3/8/4p3/3n4/4P3/8/8/4K3 w - - 0 1", "e4d5", 3 - 1},
+		// Qxd5 cxd5: a queen for a pawn.
+		{"queen takes pawn defended by pawn", "4k3/8/2p5/3p4/8/8/8/3QK3 w - - 0 1", "d1d5", 1 - 9},
+		// Rxd5 Rxd5 Rxd5: the d1 rook recaptures through the square
+		// the d2 rook left, so White keeps the knight.
+		{"x-ray through a rook battery", "3rk3/8/8/3n4/8/8/3R4/3RK3 w - - 0 1", "d2d5", 3},
+		// Without the battery the exchange stops after Rxd5.
+		{"rook takes knight defended by rook", "3rk3/8/8/3n4/8/8/3R4/4K3 w - - 0 1", "d2d5", 3 - 5},
+	}
+	for _, tt := range tests {
+		g := NewGame(0, 5)
+		if err := g.LoadFEN(tt.fen); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		before := g.FEN()
+		m, err := g.parseMove(tt.move)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := g.see(m); got != tt.want {
+			t.Errorf("%s: see = %d, want %d", tt.name, got, tt.want)
+		}
+		if got := g.FEN(); got != before {
+			t.Errorf("%s: see left the board as %s", tt.name, got)
+		}
+	}
+}
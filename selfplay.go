@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// selfPlayReasons are the result line's names for each end reason.
+var selfPlayReasons = map[string]string{
+	endCheckmate:  "checkmate",
+	endStalemate:  "stalemate",
+	endDead:       "deadposition",
+	endFiftyMove:  "fiftymove",
+	endRepetition: "repetition",
+	endPerpetual:  "perpetual",
+}
+
+// selfPlay plays Frank against himself without opening a window, prints a
+// machine-readable result line and returns the process exit code: 0 for a
+// draw, 1 if White won, 2 if Black won. Games still going after maxMoves
+// half-moves are called drawn.
+func selfPlay(maxMoves int) int {
+	g := NewGame(0, 0)
+	g.demo = true
+	reason, winner, _ := g.playSelf(maxMoves)
+
+	result, code := "1/2-1/2", 0
+	switch winner {
+	case 1:
+		result, code = "1-0", 1
+	case 0:
+		result, code = "0-1", 2
+	}
+	fmt.Printf("RESULT %s moves=%d reason=%s\n", result, (g.moveCount+1)/2, reason)
+	return code
+}
+
+// selfPlayMatch plays games self-play games back to back, for measuring an
+// engine change, and writes a GAME line for each and a summary table at the
+// end to out. Game i is seeded with i, as in strengthTest, so a run replays exactly
+// and two builds can be compared game for game. Both sides are Frank, so the
+// table counts results by color rather than by player. It returns 0.
+func selfPlayMatch(games, maxMoves int, out io.Writer) int {
+	var white, black, draws, moves, nodes, searched int
+	for i := 0; i < games; i++ {
+		g := NewGame(0, 0)
+		g.demo = true
+		g.rng = rand.New(rand.NewSource(int64(i)))
+		reason, winner, n := g.playSelf(maxMoves)
+
+		result := "1/2-1/2"
+		switch winner {
+		case 1:
+			result = "1-0"
+			white++
+		case 0:
+			result = "0-1"
+			black++
+		default:
+			draws++
+		}
+		moves += (g.moveCount + 1) / 2
+		nodes += n
+		searched += g.moveCount
+		fmt.Fprintf(out, "GAME %d seed=%d result=%s moves=%d reason=%s nodes=%d\n", i+1, i, result, (g.moveCount+1)/2, reason, n)
+	}
+
+	pct := func(n int) float64 { return 100 * float64(n) / float64(max(games, 1)) }
+	fmt.Fprintf(out, "%-12s %d\n", "games", games)
+	fmt.Fprintf(out, "%-12s %d (%.0f%%)\n", "white wins", white, pct(white))
+	fmt.Fprintf(out, "%-12s %d (%.0f%%)\n", "black wins", black, pct(black))
+	fmt.Fprintf(out, "%-12s %d (%.0f%%)\n", "draws", draws, pct(draws))
+	fmt.Fprintf(out, "%-12s %.1f\n", "avg moves", float64(moves)/float64(max(games, 1)))
+	fmt.Fprintf(out, "%-12s %.0f per move\n", "avg nodes", float64(nodes)/float64(max(searched, 1)))
+	return 0
+}
+
+// playSelf plays g out with Frank on both sides, for at most maxMoves
+// half-moves. It returns the end reason as the result line names it, the
+// winning color or -1 for a draw, and the nodes searched in all.
+func (g *Game) playSelf(maxMoves int) (reason string, winner, nodes int) {
+	reason, winner = "movelimit", -1
+	for g.moveCount < maxMoves {
+		if r, w, over := g.outcome(); over {
+			return selfPlayReasons[r], w, nodes
+		}
+		m, _ := g.frankMove(g.activeColor)
+		nodes += g.lastSearch.nodes
+		g.executeMove(m)
+	}
+	return reason, winner, nodes
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Kings are mated, never taken: no move Frank finds in self-play may leave
+// either side without its king.
+func TestSelfPlayKeepsKings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("plays whole games")
+	}
+	for seed := int64(0); seed < 3; seed++ {
+		g := NewGame(0, 0)
+		g.demo = true
+		g.rng = rand.New(rand.NewSource(seed))
+		for g.moveCount < 120 {
+			if _, _, over := g.outcome(); over {
+				break
+			}
+			g.playSelf(g.moveCount + 1) // one half-move
+			for _, c := range []Color{White, Black} {
+				if n := kingCount(g, c); n != 1 {
+					t.Fatalf("seed %d, half-move %d: color %d has %d kings in %s", seed, g.moveCount, c, n, g.FEN())
+				}
+			}
+		}
+	}
+}
+
+func kingCount(g *Game, c Color) int {
+	n := 0
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if p := g.board[y][x]; p != nil && p.Type == King && p.Color == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// A match replays exactly for the same seeds, and its table adds up: every
+// game is a white win, a black win or a draw, as its GAME line says.
+func TestSelfPlayMatchTally(t *testing.T) {
+	if testing.Short() {
+		t.Skip("plays whole games")
+	}
+	const games = 4
+	var first, second strings.Builder
+	selfPlayMatch(games, 120, &first)
+	selfPlayMatch(games, 120, &second)
+	if first.String() != second.String() {
+		t.Fatalf("same seeds, different matches:\n%s\n%s", first.String(), second.String())
+	}
+
+	results := map[string]int{}
+	table := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(first.String()), "\n") {
+		if strings.HasPrefix(line, "GAME ") {
+			for _, f := range strings.Fields(line) {
+				if r, ok := strings.CutPrefix(f, "result="); ok {
+					results[r]++
+				}
+			}
+			continue
+		}
+		for _, name := range []string{"games", "white wins", "black wins", "draws"} {
+			if rest, ok := strings.CutPrefix(line, name+" "); ok {
+				n, err := strconv.Atoi(strings.Fields(rest)[0])
+				if err != nil {
+					t.Fatalf("table line %q: %v", line, err)
+				}
+				table[name] = n
+			}
+		}
+	}
+	if table["games"] != games {
+		t.Errorf("table counts %d games, want %d", table["games"], games)
+	}
+	if sum := table["white wins"] + table["black wins"] + table["draws"]; sum != games {
+		t.Errorf("%d white wins + %d black wins + %d draws = %d, want %d", table["white wins"], table["black wins"], table["draws"], sum, games)
+	}
+	want := map[string]int{"white wins": results["1-0"], "black wins": results["0-1"], "draws": results["1/2-1/2"]}
+	for name, n := range want {
+		if table[name] != n {
+			t.Errorf("table has %d %s, GAME lines %d", table[name], name, n)
+		}
+	}
+}
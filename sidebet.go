@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Frank's side bet: once a game, when he's three or more points of material
+// down, he offers to match the stake on the player mating him within ten
+// more moves. Accepted, it is paid out on that mate and lost on anything
+// else: the deadline passing, a draw, or losing on time or on the board.
+const (
+	betTrigger  = 3  // Frank's material deficit that prompts the offer
+	betHalfMove = 20 // ten moves each to deliver the mate
+)
+
+// offerBet puts the side bet to the player if it's due.
+func (g *Game) offerBet() {
+	if g.betOffered || g.wager == 0 || g.material(White)-g.material(Black) < betTrigger {
+		return
+	}
+	g.betOffered, g.betPending = true, true
+	g.currentDialog = "Feeling lucky?"
+}
+
+// updateBetOffer takes the player's answer: Y or a tap on the offer banner
+// accepts, and N or simply making a move declines.
+func (g *Game) updateBetOffer() {
+	accept := inpututil.IsKeyJustPressed(ebiten.KeyY)
+	if _, my, ok := pointerJustPressed(); ok && my < tileSize {
+		accept = true
+	}
+	switch {
+	case accept:
+		g.betPending, g.sideBet, g.betDeadline = false, g.wager, g.moveCount+betHalfMove
+		g.currentDialog = "You're on, kid."
+	case inpututil.IsKeyJustPressed(ebiten.KeyN):
+		g.betPending, g.currentDialog = false, "Chicken."
+	}
+}
+
+// betExpired reports whether an accepted side bet has run out of moves.
+func (g *Game) betExpired() bool {
+	return g.sideBet > 0 && g.moveCount >= g.betDeadline
+}
+
+// settleBet pays out or collects an accepted side bet: only a mate by the
+// player wins it.
+func (g *Game) settleBet() {
+	if g.sideBet == 0 {
+		return
+	}
+	if g.endReason == endCheckmate && g.winner == 1 {
+		profile.Wallet += g.sideBet
+	} else {
+		profile.Wallet -= g.sideBet
+		if !g.gameOver {
+			g.currentDialog = "Ten moves, no mate. Pay up."
+		}
+	}
+	g.sideBet = 0
+	profile.save()
+}
+
+// betBanner is the offer, drawn across the top border while it stands.
+func (g *Game) betBanner() string {
+	return fmt.Sprintf("$%d: MATE IN 10? Y/N", g.wager)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// saveSnapshot renders the board, bordered and unscaled, to an offscreen
+// image and writes it as a PNG named for the current time in the working
+// directory. The export adds what the live board leaves out: the last move
+// tinted and file and rank labels on the border.
+func (g *Game) saveSnapshot() {
+	size := gridSize * tileSize
+	img := ebiten.NewImage(size, size)
+	g.drawBoard(img)
+	if g.moveCount > 0 {
+		for _, s := range []Pos{g.lastFrom, g.lastTo} {
+			px, py := g.renderSquare(s.X, s.Y)
+			vector.FillRect(img, float32(px), float32(py), tileSize, tileSize, color.NRGBA{255, 255, 0, 60}, false)
+		}
+	}
+	if !g.blindfolded() { // blindfold boards are labelled already
+		g.drawCoordinates(img)
+	}
+	writePNG(img, "chess-20060102-150405.png")
+}
+
+// saveScreenshot writes the whole window as the player sees it, HUD, dialog
+// and any menu or overlay included, at the canvas's own 160x200 rather than
+// scaled up to the window.
+func (g *Game) saveScreenshot() {
+	img := ebiten.NewImage(screenW, screenH)
+	g.drawScene(img)
+	writePNG(img, "chess-screen-20060102-150405.png")
+}
+
+// writePNG encodes img to a file in the working directory named by
+// formatting the current time with layout, and reports the outcome on the
+// terminal.
+func writePNG(img *ebiten.Image, layout string) {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	pix := make([]byte, 4*w*h)
+	img.ReadPixels(pix)
+	name := time.Now().Format(layout)
+	f, err := os.Create(name)
+	if err == nil {
+		err = png.Encode(f, &image.RGBA{Pix: pix, Stride: 4 * w, Rect: image.Rect(0, 0, w, h)})
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Printf("snapshot: %v\n", err)
+		return
+	}
+	fmt.Printf("saved %s\n", name)
+}
+
+// drawCoordinates labels the files along the bottom border and the ranks
+// down the left one.
+func (g *Game) drawCoordinates(screen *ebiten.Image) {
+	for i := 0; i < 8; i++ {
+		px, _ := g.renderSquare(i, 7)
+		_, py := g.renderSquare(0, i)
+		drawText(screen, string(rune('a'+i)), px+5, gridSize*tileSize-4, color.White)
+		drawText(screen, strconv.Itoa(8-i), 5, py+12, color.White)
+	}
+}
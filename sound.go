@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/ebitengine/oto/v3"
+)
+
+const sampleRate = 44100
+
+// Sound goes through oto, the library under Ebitengine's audio package,
+// because that package reports an audio device it can't open by ending the
+// game, from inside ebiten.RunGame. oto hands the error back instead, so a
+// machine with no sound card, like a CI runner or a headless server, just
+// plays in silence.
+var (
+	audioContext *oto.Context
+	audioReady   chan struct{} // closed once the device has been opened, or failed to
+	playing      []*oto.Player // sounds still playing, kept so they aren't collected
+
+	// soundAvailable gates every sound. It goes false for the rest of the
+	// run the first time the audio device turns out to be missing.
+	soundAvailable = true
+)
+
+// initAudio starts opening the audio device. It doesn't wait: the device
+// is ready, or known to be missing, a moment later.
+func initAudio() {
+	ctx, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   sampleRate,
+		ChannelCount: 2,
+		Format:       oto.FormatSignedInt16LE,
+	})
+	if err != nil {
+		disableSound(err)
+		return
+	}
+	audioContext, audioReady = ctx, ready
+}
+
+// disableSound turns sound off for good, with a warning on the terminal.
+func disableSound(err error) {
+	soundAvailable = false
+	fmt.Printf("sound off: %v\n", err)
+}
+
+// moveClicks are the move sounds, indexed by the mover's Color: a short,
+// quiet knock, pitched a little lower for Frank so his replies can be told
+// apart by ear.
+var moveClicks = [2][]byte{
+	Black: knock(520),
+	White: knock(660),
+}
+
+// knock synthesises a 60ms sine burst that dies away quickly, as 16-bit
+// little-endian stereo PCM.
+func knock(freq float64) []byte {
+	n := sampleRate * 60 / 1000
+	buf := make([]byte, n*4)
+	for i := 0; i < n; i++ {
+		t := float64(i) / sampleRate
+		v := int16(0.2 * math.MaxInt16 * math.Exp(-t*60) * math.Sin(2*math.Pi*freq*t))
+		binary.LittleEndian.PutUint16(buf[i*4:], uint16(v))
+		binary.LittleEndian.PutUint16(buf[i*4+2:], uint16(v))
+	}
+	return buf
+}
+
+// playMoveSound is the moveSound hook for the windowed game. A move made
+// while the device is still opening goes unheard.
+func playMoveSound(c Color) {
+	if !soundAvailable || audioContext == nil {
+		return
+	}
+	select {
+	case <-audioReady:
+	default:
+		return
+	}
+	if err := audioContext.Err(); err != nil {
+		disableSound(err)
+		return
+	}
+	still := playing[:0]
+	for _, p := range playing {
+		if p.IsPlaying() {
+			still = append(still, p)
+		}
+	}
+	p := audioContext.NewPlayer(bytes.NewReader(moveClicks[c]))
+	p.Play()
+	playing = append(still, p)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Frank plays strengthGames seeded games against a reference opponent that
+// picks uniformly among its legal moves, alternating colors, and has to
+// score at least strengthMinScore percent, a draw counting half. Game i is
+// seeded with i, so both Frank's noise and the opponent's choices replay
+// identically from run to run.
+const (
+	strengthGames    = 6
+	strengthMaxMoves = 200
+	strengthMinScore = 80
+)
+
+func TestStrength(t *testing.T) {
+	if testing.Short() {
+		t.Skip("plays whole games")
+	}
+	points := 0 // in half-points, so draws stay whole
+	for i := 0; i < strengthGames; i++ {
+		frank := Color(i % 2)
+		g := NewGame(0, 0)
+		g.demo = true
+		g.rng = rand.New(rand.NewSource(int64(i)))
+		reason, winner := "movelimit", -1
+		for g.moveCount < strengthMaxMoves {
+			if r, w, over := g.outcome(); over {
+				reason, winner = selfPlayReasons[r], w
+				break
+			}
+			if g.activeColor == frank {
+				m, _ := g.frankMove(frank)
+				g.executeMove(m)
+			} else {
+				moves := g.LegalMoves(g.activeColor)
+				g.executeMove(moves[g.rng.Intn(len(moves))])
+			}
+		}
+
+		result := "draw"
+		switch {
+		case winner == int(frank):
+			result = "win"
+			points += 2
+		case winner >= 0:
+			result = "loss"
+		default:
+			points++
+		}
+		side := "black"
+		if frank == White {
+			side = "white"
+		}
+		t.Logf("game %d seed=%d frank=%s result=%s moves=%d reason=%s", i+1, i, side, result, (g.moveCount+1)/2, reason)
+	}
+	if pct := points * 50 / strengthGames; pct < strengthMinScore {
+		t.Errorf("Frank scored %.1f/%d (%d%%), want at least %d%%", float64(points)/2, strengthGames, pct, strengthMinScore)
+	}
+}
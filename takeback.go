@@ -0,0 +1,67 @@
+package main
+
+import (
+	"maps"
+	"slices"
+)
+
+// Takebacks are bought with wallet money in staked games. Each rewinds the
+// board to just before the player's last move, taking the hustler's reply
+// back with it; the clocks, stakes and side bet carry on regardless.
+const (
+	takebackCost = 10
+	maxTakebacks = 3 // per game
+)
+
+// saveTakeback remembers the position before a move by the player, for a
+// takeback to return to.
+func (g *Game) saveTakeback() {
+	s := g.clone()
+	s.takebackTo = nil // don't chain every earlier position along
+	s.repetitions = maps.Clone(g.repetitions)
+	s.evals = slices.Clone(g.evals)
+	s.positions = slices.Clone(g.positions)
+	g.takebackTo = s
+}
+
+// canTakeBack reports whether a takeback is on offer: the hustler has
+// replied to the player's last move, that move hasn't been taken back
+// already, and the game's allowance isn't used up.
+func (g *Game) canTakeBack() bool {
+	return g.takebackTo != nil && g.assists() && g.activeColor == White && !g.promoting && !g.gameOver && g.takebacks < maxTakebacks
+}
+
+// blunderLoss is the material, in pawns, Frank's reply has to win by static
+// exchange for easy assist to call the player's move a blunder: the
+// exchange or more.
+const blunderLoss = 2
+
+// punishes reports whether Frank's reply m wins at least blunderLoss by
+// static exchange, meaning the player's move just before it left that
+// material en prise.
+func (g *Game) punishes(m Move) bool {
+	return g.board[m.To.Y][m.To.X] != nil && g.see(m) >= blunderLoss
+}
+
+// canRewind reports whether easy assist has a free takeback on offer, for
+// a blunder Frank has just punished.
+func (g *Game) canRewind() bool {
+	return g.rewindOffer && g.assists() && g.takebackTo != nil && g.activeColor == White && !g.promoting && !g.gameOver
+}
+
+// takeBack rewinds the board to the position saveTakeback kept.
+func (g *Game) takeBack() {
+	s := g.takebackTo
+	g.board, g.activeColor = s.board, s.activeColor
+	g.epX, g.epY, g.castling = s.epX, s.epY, s.castling
+	g.moveCount, g.halfmoveClock = s.moveCount, s.halfmoveClock
+	g.history, g.evals, g.repetitions = s.history, s.evals, s.repetitions
+	g.positions = s.positions
+	g.lastFrom, g.lastTo = s.lastFrom, s.lastTo
+	g.selectedX, g.selectedY, g.pendingX, g.pendingY = -1, -1, -1, -1
+	g.premoveFrom, g.premoveTo = Pos{-1, -1}, Pos{-1, -1}
+	g.slide, g.epFade = slide{}, fade{}
+	g.turnClock = g.clock(White)
+	g.takebackTo = nil
+	g.rewindOffer = false
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+// squareCenter gives the canvas position of the middle of board square x, y,
+// as clickBoard reads it on an unflipped board.
+func squareCenter(x, y int) (int, int) {
+	return (x+1)*tileSize + tileSize/2, (y+1)*tileSize + tileSize/2
+}
+
+func TestNoInputOnFranksTurn(t *testing.T) {
+	g := NewGame(0, 5)
+	playMoves(t, g, "e4")
+	before := g.FEN()
+	e7, e5 := Pos{4, 1}, Pos{4, 3}
+
+	g.clickBoard(squareCenter(e7.X, e7.Y))
+	g.clickBoard(squareCenter(e5.X, e5.Y))
+	if got := g.FEN(); got != before {
+		t.Errorf("clicks on Frank's turn moved: %s", got)
+	}
+	if g.playerMove(Move{From: e7, To: e5}) {
+		t.Error("playerMove played on Frank's turn")
+	}
+	g.premoveFrom, g.premoveTo = e7, e5
+	g.playPremove()
+	if got := g.FEN(); got != before {
+		t.Errorf("moves on Frank's turn changed the position to %s", got)
+	}
+
+	// The same clicks move Black's pawn once Black is a human's side.
+	g.hotseat = true
+	g.clickBoard(squareCenter(e7.X, e7.Y))
+	g.clickBoard(squareCenter(e5.X, e5.Y))
+	if p := g.board[e5.Y][e5.X]; p == nil || p.Type != Pawn || p.Color != Black {
+		t.Errorf("hot seat didn't let Black move: %s", g.FEN())
+	}
+}
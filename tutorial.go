@@ -0,0 +1,103 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// tutorial is the walk through the controls shown over the stakes menu the
+// first time a profile is played. It comes up when the profile has never
+// been saved, and finishing or skipping it marks the profile as tutored so
+// it doesn't come back.
+type tutorial struct {
+	page  int
+	board *Game // the practice board on tutorialMovePage
+}
+
+// tutorialPages are the tutorial's pages, a heading and then its lines. The
+// page at tutorialMovePage has a practice board instead of text.
+var tutorialPages = [][]string{
+	{"WELCOME TO THE PARK", "Frank plays chess", "for money. Beat him", "and his cash is", "yours."},
+	{"YOUR MOVE", "Click e2, then e4."},
+	{"PROMOTING", "A pawn that reaches", "the far end opens a", "picker: Q R B N.", "Click one or press", "its key."},
+	{"STAKES", "On the menu, 1 or 2", "puts money down on", "a game. After a", "3, 2, 1 the clocks", "run.", "", "O: settings"},
+}
+
+const tutorialMovePage = 1
+
+// updateTutorial runs the tutorial: Enter, Space or a click turns the page,
+// and Escape skips the rest. On the practice page clicks go to the board
+// until a move has been played.
+func (g *Game) updateTutorial() {
+	t := g.tutorial
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.finishTutorial()
+		return
+	}
+	next := inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace)
+	mx, my, tapped := pointerJustPressed()
+	if t.page == tutorialMovePage {
+		t.board.tickAnimations()
+		if tapped && t.board.moveCount == 0 {
+			t.board.clickBoard(mx, my)
+			tapped = false
+		}
+	}
+	if !next && !tapped {
+		return
+	}
+	t.page++
+	switch t.page {
+	case tutorialMovePage:
+		t.board = NewGame(0, 0)
+	case len(tutorialPages):
+		g.finishTutorial()
+	}
+}
+
+// finishTutorial puts the tutorial away for good.
+func (g *Game) finishTutorial() {
+	g.tutorial = nil
+	profile.Tutored = true
+	profile.save()
+}
+
+// drawTutorial draws the tutorial's current page.
+func (g *Game) drawTutorial(screen *ebiten.Image) {
+	t := g.tutorial
+	lines := tutorialPages[t.page]
+	gold := color.RGBA{255, 215, 0, 255}
+	if t.page == tutorialMovePage {
+		t.board.drawBoard(screen)
+		dy := gridSize * tileSize
+		if t.board.moveCount > 0 {
+			drawText(screen, "THAT'S A MOVE!", 5, dy+12, gold)
+			drawText(screen, "Frank waits his turn", 5, dy+24, color.White)
+			drawText(screen, "CLICK:next ESC:skip", 5, dy+36, color.White)
+			return
+		}
+		// Point at the pawn, then at where it goes.
+		guide := Pos{4, 6}
+		if t.board.selectedX >= 0 {
+			guide = Pos{4, 4}
+		}
+		px, py := t.board.renderSquare(guide.X, guide.Y)
+		vector.StrokeRect(screen, float32(px)+0.5, float32(py)+0.5, tileSize-1, tileSize-1, 1, gold, false)
+		drawText(screen, lines[0], 5, dy+12, gold)
+		drawText(screen, lines[1], 5, dy+24, color.White)
+		drawText(screen, "ESC:skip", 5, dy+36, color.White)
+		return
+	}
+	if g.demoGame != nil {
+		g.demoGame.drawBoard(screen)
+	}
+	vector.FillRect(screen, 10, 28, 140, 162, color.RGBA{0, 0, 0, 200}, false)
+	drawText(screen, lines[0], 15, stakesHeaderBaseline, gold)
+	for i, line := range lines[1:] {
+		drawText(screen, line, 15, 60+i*13, color.White)
+	}
+	drawText(screen, "CLICK:next ESC:skip", 15, settingsBackBaseline, color.RGBA{150, 150, 150, 255})
+}
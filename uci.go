@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// uciLoop speaks enough of the UCI protocol on in and out for a chess GUI to
+// play Frank: uci, isready, ucinewgame, position, go and quit. Of go's limits
+// only depth and movetime are kept, and without either Frank searches to his
+// usual depth; anything else is skipped, as the protocol asks. It returns the
+// process exit code.
+func uciLoop(in io.Reader, out io.Writer) int {
+	g := newUCIGame()
+	sc := bufio.NewScanner(in)
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) == 0 {
+			continue
+		}
+		switch f[0] {
+		case "uci":
+			fmt.Fprintln(out, "id name 4-Move-Frank")
+			fmt.Fprintln(out, "id author ngolebiewski")
+			fmt.Fprintln(out, "uciok")
+		case "isready":
+			fmt.Fprintln(out, "readyok")
+		case "ucinewgame":
+			g = newUCIGame()
+		case "position":
+			ng, err := uciPosition(f[1:])
+			if err != nil {
+				fmt.Fprintln(out, "info string", err)
+				continue
+			}
+			g = ng
+		case "go":
+			g.budget = uciBudget(f[1:])
+			m, ok := g.frankMove(g.activeColor)
+			if !ok {
+				fmt.Fprintln(out, "bestmove 0000")
+				continue
+			}
+			fmt.Fprintf(out, "info depth %d nodes %d pv %s\n", g.lastSearch.depth, g.lastSearch.nodes, g.lineText(g.lastSearch.pv, true))
+			c := g.clone()
+			c.executeMove(m)
+			fmt.Fprintln(out, "bestmove", c.lastUCI())
+		case "quit":
+			return 0
+		}
+	}
+	return 0
+}
+
+// uciBudget reads the depth or movetime limit from a go command's arguments.
+func uciBudget(args []string) searchBudget {
+	var b searchBudget
+	for i := 0; i+1 < len(args); i++ {
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n <= 0 {
+			continue
+		}
+		switch args[i] {
+		case "depth":
+			b.depth = min(n, maxSearchDepth)
+		case "movetime":
+			b.millis = n
+		}
+	}
+	return b
+}
+
+// newUCIGame is a silent game from the standard position: nothing printed to
+// stdout may get in the way of the protocol.
+func newUCIGame() *Game {
+	g := NewGame(0, 0)
+	g.demo = true
+	return g
+}
+
+// uciPosition builds the game a position command describes: "startpos" or
+// "fen" and a FEN, then optionally "moves" and the moves played since, in
+// long algebraic notation.
+func uciPosition(args []string) (*Game, error) {
+	g := newUCIGame()
+	i := slices.Index(args, "moves")
+	if i < 0 {
+		i = len(args)
+	}
+	switch {
+	case len(args) > 0 && args[0] == "startpos":
+	case len(args) > 0 && args[0] == "fen":
+		if err := g.LoadFEN(strings.Join(args[1:i], " ")); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("position: want startpos or fen")
+	}
+	for _, s := range args[min(i+1, len(args)):] {
+		m, err := g.parseMove(s)
+		if err != nil {
+			return nil, fmt.Errorf("position: %s: %s", s, strings.ToLower(err.Error()))
+		}
+		g.executeMove(m)
+	}
+	return g, nil
+}
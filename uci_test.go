@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// uciSession runs commands through uciLoop and returns its output lines.
+func uciSession(t *testing.T, commands ...string) []string {
+	t.Helper()
+	var out strings.Builder
+	if code := uciLoop(strings.NewReader(strings.Join(commands, "\n")), &out); code != 0 {
+		t.Fatalf("uciLoop exited %d", code)
+	}
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+// bestMove is the move in the session's last bestmove line.
+func bestMove(t *testing.T, lines []string) string {
+	t.Helper()
+	for i := len(lines) - 1; i >= 0; i-- {
+		if s, ok := strings.CutPrefix(lines[i], "bestmove "); ok {
+			return s
+		}
+	}
+	t.Fatalf("no bestmove in %q", lines)
+	return ""
+}
+
+func TestUCISession(t *testing.T) {
+	lines := uciSession(t,
+		"uci",
+		"isready",
+		"ucinewgame",
+		"position startpos moves e2e4",
+		"go",
+		"quit",
+		"isready", // after quit: never answered
+	)
+	if i := strings.Index(strings.Join(lines, "\n"), "uciok\nreadyok\n"); i < 0 {
+		t.Fatalf("want uciok then readyok, got %q", lines)
+	}
+	if strings.Count(strings.Join(lines, "\n"), "readyok") != 1 {
+		t.Errorf("commands after quit were answered: %q", lines)
+	}
+	g := NewGame(0, 0)
+	playMoves(t, g, "e2e4")
+	mv := bestMove(t, lines)
+	m, err := g.parseMove(mv)
+	if err != nil {
+		t.Fatalf("bestmove %s after 1.e4: %v", mv, err)
+	}
+	if p := g.board[m.From.Y][m.From.X]; p.Color != Black {
+		t.Errorf("bestmove %s moves White's piece", mv)
+	}
+}
+
+func TestUCIPositions(t *testing.T) {
+	tests := []struct {
+		name, position string
+		want           string // the only legal move; "" when any legal one will do
+	}{
+		{"fen", "position fen 7k/8/8/8/8/8/6PP/r5K1 w - - 0 1", ""},
+		{"fen and moves", "position fen 4k3/8/8/8/8/8/8/R3K3 w Q - 0 1 moves e1c1 e8e7", ""},
+		{"forced", "position fen 7k/8/8/8/8/8/6r1/7K w - - 0 1", "h1g2"},
+		{"mated", "position fen 7k/8/8/8/8/8/5PPP/r5K1 w - - 0 1", "0000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := uciSession(t, tt.position, "go depth 2")
+			mv := bestMove(t, lines)
+			if tt.want != "" {
+				if mv != tt.want {
+					t.Errorf("bestmove %s, want %s", mv, tt.want)
+				}
+				return
+			}
+			g, err := uciPosition(strings.Fields(tt.position)[1:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := g.parseMove(mv); err != nil {
+				t.Errorf("bestmove %s: %v", mv, err)
+			}
+		})
+	}
+}
+
+// A position that doesn't parse is reported and the last good one kept.
+func TestUCIBadPosition(t *testing.T) {
+	lines := uciSession(t,
+		"position startpos moves e2e4",
+		"position startpos moves e2e5",
+		"go depth 1",
+	)
+	if !strings.HasPrefix(lines[0], "info string position: e2e5") {
+		t.Errorf("first line %q, want the bad move reported", lines[0])
+	}
+	g := NewGame(0, 0)
+	playMoves(t, g, "e2e4")
+	if _, err := g.parseMove(bestMove(t, lines)); err != nil {
+		t.Errorf("bestmove doesn't fit 1.e4: %v", err)
+	}
+}